@@ -0,0 +1,141 @@
+package benchmarks
+
+import (
+	"bytes"
+	"encoding/json"
+	"json-parser/parser"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// decodable is implemented by the generated methods.
+type decodable interface {
+	DecodeJSONToken(l *parser.Lexer, tok parser.Token) error
+	AppendJSON(buf []byte) []byte
+}
+
+// decodeGenerated decodes a top-level array with the generated methods.
+func decodeGenerated[T any, PT interface {
+	*T
+	decodable
+}](data []byte) ([]T, error) {
+	l := parser.NewLexer(bytes.NewReader(data))
+	tok, err := l.NextToken()
+	if err != nil {
+		return nil, err
+	}
+	var out []T
+	err = parser.DecodeArray(l, tok, func(l *parser.Lexer, tok parser.Token) error {
+		var v T
+		err := PT(&v).DecodeJSONToken(l, tok)
+		out = append(out, v)
+		return err
+	})
+	return out, err
+}
+
+func readFixture(tb testing.TB, name string) []byte {
+	tb.Helper()
+	data, err := os.ReadFile("../test_data/example_" + name + ".json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return data
+}
+
+func checkFixture[T any, PT interface {
+	*T
+	decodable
+}](t *testing.T, name string) {
+	data := readFixture(t, name)
+	got, err := decodeGenerated[T, PT](data)
+	if err != nil {
+		t.Fatalf("generated decoder: %v", err)
+	}
+	var want []T
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("generated decoder disagrees with encoding/json")
+	}
+	for i := range got {
+		var back T
+		if err := json.Unmarshal(PT(&got[i]).AppendJSON(nil), &back); err != nil || !reflect.DeepEqual(back, got[i]) {
+			t.Fatalf("record %d does not round trip: %v", i, err)
+		}
+	}
+}
+
+func TestGeneratedDecodersMatchReflection(t *testing.T) {
+	t.Run("albums", func(t *testing.T) { checkFixture[Album](t, "albums") })
+	t.Run("posts", func(t *testing.T) { checkFixture[Post](t, "posts") })
+	t.Run("todos", func(t *testing.T) { checkFixture[Todo](t, "todos") })
+	t.Run("users", func(t *testing.T) { checkFixture[User](t, "users") })
+}
+
+func benchmarkFixture[T any, PT interface {
+	*T
+	decodable
+}](b *testing.B, name string) {
+	data := readFixture(b, name)
+	b.Run("generated", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := decodeGenerated[T, PT](data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reflect", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			var out []T
+			if err := json.Unmarshal(data, &out); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("BasicParase", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			parser.BasicParase(bytes.NewReader(data))
+		}
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	b.Run("albums", func(b *testing.B) { benchmarkFixture[Album](b, "albums") })
+	b.Run("posts", func(b *testing.B) { benchmarkFixture[Post](b, "posts") })
+	b.Run("todos", func(b *testing.B) { benchmarkFixture[Todo](b, "todos") })
+	b.Run("users", func(b *testing.B) { benchmarkFixture[User](b, "users") })
+}
+
+func BenchmarkEncode(b *testing.B) {
+	users, err := decodeGenerated[User](readFixture(b, "users"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		var buf []byte
+		for b.Loop() {
+			buf = buf[:0]
+			for i := range users {
+				buf = users[i].AppendJSON(buf)
+			}
+		}
+	})
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := json.Marshal(users); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
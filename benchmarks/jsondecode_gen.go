@@ -0,0 +1,451 @@
+// Code generated by jsondecoder. DO NOT EDIT.
+
+package benchmarks
+
+import (
+	"strconv"
+
+	"json-parser/parser"
+)
+
+// DecodeJSON reads one Album from l.
+func (v *Album) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Album whose first token, tok, was already read.
+func (v *Album) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "userId":
+			if tok.Type != parser.TokenNull {
+				n1, e := parser.DecodeInt(tok, 0)
+				v.UserID, err = int(n1), e
+			}
+		case "id":
+			if tok.Type != parser.TokenNull {
+				n2, e := parser.DecodeInt(tok, 0)
+				v.ID, err = int(n2), e
+			}
+		case "title":
+			if tok.Type != parser.TokenNull {
+				v.Title, err = parser.DecodeString(tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Album) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"userId\":"...)
+	buf = strconv.AppendInt(buf, int64(v.UserID), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"id\":"...)
+	buf = strconv.AppendInt(buf, int64(v.ID), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"title\":"...)
+	buf = parser.AppendString(buf, v.Title)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+// DecodeJSON reads one Post from l.
+func (v *Post) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Post whose first token, tok, was already read.
+func (v *Post) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "userId":
+			if tok.Type != parser.TokenNull {
+				n3, e := parser.DecodeInt(tok, 0)
+				v.UserID, err = int(n3), e
+			}
+		case "id":
+			if tok.Type != parser.TokenNull {
+				n4, e := parser.DecodeInt(tok, 0)
+				v.ID, err = int(n4), e
+			}
+		case "title":
+			if tok.Type != parser.TokenNull {
+				v.Title, err = parser.DecodeString(tok)
+			}
+		case "body":
+			if tok.Type != parser.TokenNull {
+				v.Body, err = parser.DecodeString(tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Post) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"userId\":"...)
+	buf = strconv.AppendInt(buf, int64(v.UserID), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"id\":"...)
+	buf = strconv.AppendInt(buf, int64(v.ID), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"title\":"...)
+	buf = parser.AppendString(buf, v.Title)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"body\":"...)
+	buf = parser.AppendString(buf, v.Body)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+// DecodeJSON reads one Todo from l.
+func (v *Todo) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Todo whose first token, tok, was already read.
+func (v *Todo) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "userId":
+			if tok.Type != parser.TokenNull {
+				n5, e := parser.DecodeInt(tok, 0)
+				v.UserID, err = int(n5), e
+			}
+		case "id":
+			if tok.Type != parser.TokenNull {
+				n6, e := parser.DecodeInt(tok, 0)
+				v.ID, err = int(n6), e
+			}
+		case "title":
+			if tok.Type != parser.TokenNull {
+				v.Title, err = parser.DecodeString(tok)
+			}
+		case "completed":
+			if tok.Type != parser.TokenNull {
+				v.Completed, err = parser.DecodeBool(tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Todo) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"userId\":"...)
+	buf = strconv.AppendInt(buf, int64(v.UserID), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"id\":"...)
+	buf = strconv.AppendInt(buf, int64(v.ID), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"title\":"...)
+	buf = parser.AppendString(buf, v.Title)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"completed\":"...)
+	buf = strconv.AppendBool(buf, v.Completed)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+// DecodeJSON reads one User from l.
+func (v *User) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one User whose first token, tok, was already read.
+func (v *User) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "id":
+			if tok.Type != parser.TokenNull {
+				n7, e := parser.DecodeInt(tok, 0)
+				v.ID, err = int(n7), e
+			}
+		case "name":
+			if tok.Type != parser.TokenNull {
+				v.Name, err = parser.DecodeString(tok)
+			}
+		case "username":
+			if tok.Type != parser.TokenNull {
+				v.Username, err = parser.DecodeString(tok)
+			}
+		case "email":
+			if tok.Type != parser.TokenNull {
+				v.Email, err = parser.DecodeString(tok)
+			}
+		case "address":
+			if tok.Type != parser.TokenNull {
+				err = v.Address.DecodeJSONToken(l, tok)
+			}
+		case "phone":
+			if tok.Type != parser.TokenNull {
+				v.Phone, err = parser.DecodeString(tok)
+			}
+		case "website":
+			if tok.Type != parser.TokenNull {
+				v.Website, err = parser.DecodeString(tok)
+			}
+		case "company":
+			if tok.Type != parser.TokenNull {
+				err = v.Company.DecodeJSONToken(l, tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *User) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"id\":"...)
+	buf = strconv.AppendInt(buf, int64(v.ID), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"name\":"...)
+	buf = parser.AppendString(buf, v.Name)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"username\":"...)
+	buf = parser.AppendString(buf, v.Username)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"email\":"...)
+	buf = parser.AppendString(buf, v.Email)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"address\":"...)
+	buf = v.Address.AppendJSON(buf)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"phone\":"...)
+	buf = parser.AppendString(buf, v.Phone)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"website\":"...)
+	buf = parser.AppendString(buf, v.Website)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"company\":"...)
+	buf = v.Company.AppendJSON(buf)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+// DecodeJSON reads one Address from l.
+func (v *Address) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Address whose first token, tok, was already read.
+func (v *Address) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "street":
+			if tok.Type != parser.TokenNull {
+				v.Street, err = parser.DecodeString(tok)
+			}
+		case "suite":
+			if tok.Type != parser.TokenNull {
+				v.Suite, err = parser.DecodeString(tok)
+			}
+		case "city":
+			if tok.Type != parser.TokenNull {
+				v.City, err = parser.DecodeString(tok)
+			}
+		case "zipcode":
+			if tok.Type != parser.TokenNull {
+				v.Zipcode, err = parser.DecodeString(tok)
+			}
+		case "geo":
+			if tok.Type != parser.TokenNull {
+				err = v.Geo.DecodeJSONToken(l, tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Address) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"street\":"...)
+	buf = parser.AppendString(buf, v.Street)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"suite\":"...)
+	buf = parser.AppendString(buf, v.Suite)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"city\":"...)
+	buf = parser.AppendString(buf, v.City)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"zipcode\":"...)
+	buf = parser.AppendString(buf, v.Zipcode)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"geo\":"...)
+	buf = v.Geo.AppendJSON(buf)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+// DecodeJSON reads one Company from l.
+func (v *Company) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Company whose first token, tok, was already read.
+func (v *Company) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "name":
+			if tok.Type != parser.TokenNull {
+				v.Name, err = parser.DecodeString(tok)
+			}
+		case "catchPhrase":
+			if tok.Type != parser.TokenNull {
+				v.CatchPhrase, err = parser.DecodeString(tok)
+			}
+		case "bs":
+			if tok.Type != parser.TokenNull {
+				v.Bs, err = parser.DecodeString(tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Company) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"name\":"...)
+	buf = parser.AppendString(buf, v.Name)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"catchPhrase\":"...)
+	buf = parser.AppendString(buf, v.CatchPhrase)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"bs\":"...)
+	buf = parser.AppendString(buf, v.Bs)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+// DecodeJSON reads one Geo from l.
+func (v *Geo) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Geo whose first token, tok, was already read.
+func (v *Geo) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "lat":
+			if tok.Type != parser.TokenNull {
+				v.Lat, err = parser.DecodeString(tok)
+			}
+		case "lng":
+			if tok.Type != parser.TokenNull {
+				v.Lng, err = parser.DecodeString(tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Geo) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"lat\":"...)
+	buf = parser.AppendString(buf, v.Lat)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"lng\":"...)
+	buf = parser.AppendString(buf, v.Lng)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
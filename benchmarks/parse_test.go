@@ -0,0 +1,155 @@
+package benchmarks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"json-parser/parser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parsers are the entry points building untyped values that are compared
+// by BenchmarkParse.
+var parsers = []struct {
+	name  string
+	parse func(data []byte) (any, error)
+}{
+	{"BasicParase", func(data []byte) (any, error) {
+		return parser.BasicParase(bytes.NewReader(data)), nil
+	}},
+	{"ParseDialect", func(data []byte) (any, error) {
+		return parser.ParseDialect(bytes.NewReader(data), parser.JSON)
+	}},
+	{"ParseArrayParallel", func(data []byte) (any, error) {
+		return parser.ParseArrayParallel(data, parser.ParallelOptions{ChunkSize: 64 << 10}), nil
+	}},
+	{"encoding/json", func(data []byte) (any, error) {
+		var v any
+		err := json.Unmarshal(data, &v)
+		return v, err
+	}},
+}
+
+// generated are large inputs exercising one kind of value each.
+var generated = []struct {
+	name string
+	gen  func() []byte
+}{
+	{"records", func() []byte {
+		var b strings.Builder
+		b.WriteString("[")
+		for i := range 20000 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, `{"userId":%d,"id":%d,"title":"record number %d","completed":%v,"tags":["a","b"]}`, i/10, i, i, i%3 == 0)
+		}
+		b.WriteString("]")
+		return []byte(b.String())
+	}},
+	{"numbers", func() []byte {
+		var b strings.Builder
+		b.WriteString("[")
+		for i := range 100000 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, "%d.%de%d", i, i%997, i%40-20)
+		}
+		b.WriteString("]")
+		return []byte(b.String())
+	}},
+	{"strings", func() []byte {
+		var b strings.Builder
+		b.WriteString("[")
+		for i := range 5000 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, `"%s line %d\n\t\"quoted\" café 😀"`, strings.Repeat("text ", 40), i)
+		}
+		b.WriteString("]")
+		return []byte(b.String())
+	}},
+	{"nested", func() []byte {
+		var b strings.Builder
+		b.WriteString("[")
+		for i := range 200 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(strings.Repeat(`{"a":[`, 100))
+			b.WriteString("1")
+			b.WriteString(strings.Repeat(`]}`, 100))
+		}
+		b.WriteString("]")
+		return []byte(b.String())
+	}},
+}
+
+type input struct {
+	name string
+	data []byte
+}
+
+func benchmarkInputs(tb testing.TB) []input {
+	var inputs []input
+	for _, name := range []string{"albums", "posts", "todos", "users"} {
+		inputs = append(inputs, input{name, readFixture(tb, name)})
+	}
+	for _, g := range generated {
+		inputs = append(inputs, input{g.name, g.gen()})
+	}
+	return inputs
+}
+
+func TestParsersAgree(t *testing.T) {
+	for _, in := range benchmarkInputs(t) {
+		want, err := parsers[len(parsers)-1].parse(in.data)
+		if err != nil {
+			t.Fatalf("%s: %v", in.name, err)
+		}
+		for _, p := range parsers {
+			got, err := p.parse(in.data)
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s: %s disagrees with encoding/json: %v", in.name, p.name, err)
+			}
+		}
+		var a parser.Arena
+		v, err := a.Parse(in.data, parser.JSON)
+		if err != nil || !reflect.DeepEqual(v.Interface(), want) {
+			t.Fatalf("%s: Arena disagrees with encoding/json: %v", in.name, err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, in := range benchmarkInputs(b) {
+		b.Run(in.name, func(b *testing.B) {
+			for _, p := range parsers {
+				b.Run(p.name, func(b *testing.B) {
+					b.SetBytes(int64(len(in.data)))
+					b.ReportAllocs()
+					for b.Loop() {
+						if _, err := p.parse(in.data); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+			b.Run("Arena", func(b *testing.B) {
+				b.SetBytes(int64(len(in.data)))
+				b.ReportAllocs()
+				var a parser.Arena
+				for b.Loop() {
+					a.Reset()
+					if _, err := a.Parse(in.data, parser.JSON); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"json-parser/parser"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Exit codes shared by every command.
+const (
+	exitOK      = 0 // success, or no differences
+	exitInvalid = 1 // invalid input, failed validation or differences found
+	exitUsage   = 2 // bad arguments or unreadable files
+)
+
+// cli runs one command line against the given streams.
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+type command struct {
+	name    string
+	args    string // argument synopsis for the usage line
+	summary string
+	// run registers the command's flags on a flag set from c.flagSet(cmd),
+	// parses args with c.parseFlags and carries out the command
+	run func(c *cli, cmd *command, args []string) int
+}
+
+var commands = []*command{
+	validateCommand,
+	fmtCommand,
+	minifyCommand,
+	queryCommand,
+	tokensCommand,
+	statsCommand,
+	convertCommand,
+	diffCommand,
+	patchCommand,
+	ingestCommand,
+	mergePatchCommand,
+	createMergePatchCommand,
+	inferSchemaCommand,
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		c.usage(c.stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) == 1 {
+			c.usage(c.stdout)
+			return exitOK
+		}
+		cmd := lookupCommand(args[1])
+		if cmd == nil {
+			fmt.Fprintf(c.stderr, "unknown command %q\n", args[1])
+			return exitUsage
+		}
+		// asking a command for -h prints its usage to stderr; requested
+		// help goes to stdout
+		help := &cli{stdin: c.stdin, stdout: c.stdout, stderr: c.stdout}
+		return cmd.run(help, cmd, []string{"-h"})
+	}
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(c.stderr, "unknown command %q\n", args[0])
+		c.usage(c.stderr)
+		return exitUsage
+	}
+	return cmd.run(c, cmd, args[1:])
+}
+
+func (c *cli) usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: json-parser <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-19s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Files may be given as paths or glob patterns; "-" or no file reads stdin.`)
+	fmt.Fprintln(w, `Run "json-parser help <command>" for the flags of a command.`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "exit status: 0 ok, 1 invalid input or differences found, 2 usage error")
+}
+
+func (c *cli) flagSet(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "usage: json-parser %s [flags] %s\n\n%s\n", cmd.name, cmd.args, cmd.summary)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(w, "\nflags:")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// parseFlags parses the command line of a command. When ok is false the
+// command must return code right away: the user asked for help or the
+// flags were invalid.
+func (c *cli) parseFlags(fs *flag.FlagSet, args []string) (rest []string, code int, ok bool) {
+	rest, err := parseInterspersed(fs, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, exitOK, false
+	}
+	if err != nil {
+		return nil, exitUsage, false
+	}
+	return rest, exitOK, true
+}
+
+// parseInterspersed parses flags that appear before, between or after the
+// positional arguments, which the flag package alone does not allow.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// usageError reports a bad invocation of the command and returns exitUsage.
+func (c *cli) usageError(fs *flag.FlagSet, format string, args ...any) int {
+	fmt.Fprintf(c.stderr, "json-parser %s: %s\n", fs.Name(), fmt.Sprintf(format, args...))
+	fmt.Fprintf(c.stderr, "run \"json-parser help %s\" for usage\n", fs.Name())
+	return exitUsage
+}
+
+// input is one document read from a file or stdin.
+type input struct {
+	name    string // the path, or "<stdin>"
+	data    []byte
+	dialect parser.Dialect
+}
+
+const stdinName = "<stdin>"
+
+// readInputs expands glob patterns and reads every file; no arguments or
+// "-" read stdin. dialect is the value of the --dialect flag.
+func (c *cli) readInputs(args []string, dialect string) ([]input, error) {
+	paths, err := expandArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	inputs := make([]input, 0, len(paths))
+	for _, path := range paths {
+		in, err := c.readInput(path, dialect)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+// expandArgs expands the glob patterns among file arguments, defaulting to
+// "-" for stdin.
+func expandArgs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+	var paths []string
+	for _, arg := range args {
+		if arg == "-" || !strings.ContainsAny(arg, "*?[") {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", arg)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+func (c *cli) readInput(path, dialect string) (input, error) {
+	in := input{name: path}
+	var err error
+	if path == "-" {
+		in.name = stdinName
+		in.data, err = io.ReadAll(c.stdin)
+	} else {
+		in.data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return input{}, err
+	}
+	in.dialect, err = dialectOf(in.name, dialect)
+	return in, err
+}
+
+// dialectOf resolves the --dialect flag; "auto" picks the dialect from the
+// file extension.
+func dialectOf(name, flagValue string) (parser.Dialect, error) {
+	switch flagValue {
+	case "json":
+		return parser.JSON, nil
+	case "jsonc":
+		return parser.JSONC, nil
+	case "json5":
+		return parser.JSON5, nil
+	case "auto", "":
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".jsonc":
+			return parser.JSONC, nil
+		case ".json5":
+			return parser.JSON5, nil
+		}
+		return parser.JSON, nil
+	}
+	return 0, fmt.Errorf("unknown dialect %q (want json, jsonc, json5 or auto)", flagValue)
+}
+
+func dialectFlag(fs *flag.FlagSet) *string {
+	return fs.String("dialect", "auto", "input dialect: json, jsonc, json5, or auto to go by file extension")
+}
+
+// parse decodes an input in its dialect.
+func (in input) parse() (any, error) {
+	return parser.ParseDialect(bytes.NewReader(in.data), in.dialect)
+}
+
+// lineCol returns the line and rune column of offset, both 1-based.
+func (in input) lineCol(offset int) (line, col int) {
+	offset = min(offset, len(in.data))
+	line, col = 1, 1
+	for _, b := range in.data[:offset] {
+		if b == '\n' {
+			line, col = line+1, 1
+		} else if b < utf8.RuneSelf || utf8.RuneStart(b) {
+			col++
+		}
+	}
+	return line, col
+}
+
+// location formats offset as file:line:column.
+func (in input) location(offset int) string {
+	line, col := in.lineCol(offset)
+	return fmt.Sprintf("%s:%d:%d", in.name, line, col)
+}
+
+// fail prints an error about an input, located when it is a syntax error,
+// and returns exitInvalid.
+func (c *cli) fail(in input, err error) int {
+	var serr *parser.SyntaxError
+	if errors.As(err, &serr) {
+		fmt.Fprintf(c.stderr, "%s: %s\n", in.location(serr.Offset), serr.Msg)
+	} else {
+		fmt.Fprintf(c.stderr, "%s: %v\n", in.name, err)
+	}
+	return exitInvalid
+}
+
+// writeJSON prints v as compact JSON on a line of its own.
+func (c *cli) writeJSON(v any) int {
+	out, err := parser.Marshal(v)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitInvalid
+	}
+	fmt.Fprintln(c.stdout, string(out))
+	return exitOK
+}
+
+// writeIndented prints v as indented JSON.
+func (c *cli) writeIndented(v any) int {
+	out, err := parser.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return exitInvalid
+	}
+	fmt.Fprintln(c.stdout, string(out))
+	return exitOK
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCLI(stdin string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	c := &cli{stdin: strings.NewReader(stdin), stdout: &out, stderr: &errOut}
+	code = c.run(args)
+	return code, out.String(), errOut.String()
+}
+
+func TestCLI(t *testing.T) {
+	tests := []struct {
+		name   string
+		stdin  string
+		args   []string
+		code   int
+		stdout string // expected stdout, unless empty
+		stderr string // substring of stderr, unless empty
+	}{
+		{name: "no command", code: exitUsage, stderr: "usage: json-parser"},
+		{name: "unknown command", args: []string{"frobnicate"}, code: exitUsage, stderr: `unknown command "frobnicate"`},
+		{name: "help", args: []string{"help", "fmt"}, code: exitOK},
+		{name: "bad flag", args: []string{"fmt", "--nope"}, code: exitUsage, stderr: "flag provided but not defined"},
+		{name: "missing file", args: []string{"validate", "does-not-exist.json"}, code: exitUsage, stderr: "does-not-exist.json"},
+		{name: "unmatched glob", args: []string{"validate", "nothing-*.json"}, code: exitUsage, stderr: `no files match "nothing-*.json"`},
+
+		{name: "validate ok", stdin: `{"a": [1, 2]}`, args: []string{"validate"}, code: exitOK, stdout: "<stdin>: ok\n"},
+		{name: "validate errors", stdin: "{\"a\": 1\n \"b\": 2,}", args: []string{"validate"}, code: exitInvalid,
+			stdout: "<stdin>:1:8: error: missing comma\n<stdin>:2:8: error: trailing comma\n"},
+		{name: "validate dialect", stdin: `{a: 1,}`, args: []string{"validate", "--dialect", "json5"}, code: exitOK},
+		{name: "validate warning", stdin: `{"a": 1, "a": 2}`, args: []string{"validate"}, code: exitOK},
+		{name: "validate json", stdin: `[1 2]`, args: []string{"validate", "--json"}, code: exitInvalid,
+			stdout: `[
+  {
+    "diagnostics": [
+      {
+        "column": 3,
+        "line": 1,
+        "message": "missing comma",
+        "severity": "error"
+      }
+    ],
+    "file": "<stdin>",
+    "valid": false
+  }
+]
+`},
+		{name: "validate schema", args: []string{"validate", "--schema", "test_data/users.schema.json", "test_data/example_users.json"}, code: exitOK},
+
+		{name: "fmt", stdin: `{"b":1, // one
+"a":[true,null,],}`, args: []string{"fmt", "--dialect", "jsonc", "--indent", "4"}, code: exitOK,
+			stdout: "{\n    \"b\": 1, // one\n    \"a\": [\n        true,\n        null\n    ]\n}\n"},
+		{name: "fmt check", stdin: `{"a":1}`, args: []string{"fmt", "--check"}, code: exitInvalid, stdout: "<stdin>\n"},
+		{name: "fmt syntax error", stdin: `{"a" 1}`, args: []string{"fmt"}, code: exitInvalid, stderr: "<stdin>:"},
+		{name: "minify", stdin: "{a: [1, 2,], /* x */ b: 'c'}", args: []string{"minify", "--dialect", "json5"}, code: exitOK,
+			stdout: "{a:[1,2],b:'c'}\n"},
+
+		{name: "query", stdin: `{"a": {"b": ["x", "y"]}}`, args: []string{"query", "/a/b/1"}, code: exitOK, stdout: "\"y\"\n"},
+		{name: "query raw", stdin: `{"a": {"b": ["x", "y"]}}`, args: []string{"query", "--raw", "/a/b/1"}, code: exitOK, stdout: "y\n"},
+		{name: "query compact", stdin: `{"a": {"b": ["x", "y"]}}`, args: []string{"query", "/a", "--compact"}, code: exitOK, stdout: "{\"b\":[\"x\",\"y\"]}\n"},
+		{name: "query missing", stdin: `{"a": 1}`, args: []string{"query", "/b"}, code: exitInvalid, stderr: "not found"},
+		{name: "query no pointer", args: []string{"query"}, code: exitUsage, stderr: "missing pointer"},
+
+		{name: "tokens", stdin: "{\"a\":\n 1}", args: []string{"tokens"}, code: exitOK,
+			stdout: "1:1\t'{'\t{\n1:2\tstring\t\"a\"\n1:5\t':'\t:\n2:2\tnumber\t1\n2:3\t'}'\t}\n"},
+		{name: "tokens error", stdin: `[1, @]`, args: []string{"tokens"}, code: exitInvalid, stderr: "<stdin>:1:5: unexpected character: @"},
+		{name: "stats", stdin: `{"a": [1, "x", true, null], "b": {}}`, args: []string{"stats"}, code: exitOK,
+			stdout: "<stdin>: 36 bytes, depth 3, 2 objects (2 keys), 1 arrays, 1 strings, 1 numbers, 1 booleans, 1 nulls\n"},
+
+		{name: "convert json", stdin: "{b: 0x10, a: 'x', c: +1.5,}", args: []string{"convert", "--dialect", "json5", "--indent", "0"}, code: exitOK,
+			stdout: "{\"b\":16,\"a\":\"x\",\"c\":1.5}\n"},
+		{name: "convert infinity", stdin: "[Infinity]", args: []string{"convert", "--dialect", "json5"}, code: exitInvalid, stderr: "<stdin>:1:2: Infinity cannot be represented in JSON"},
+		{name: "convert jsonl", stdin: `[{"b": 1, "a": 2}, [3]]`, args: []string{"convert", "--to", "jsonl"}, code: exitOK,
+			stdout: "{\"b\":1,\"a\":2}\n[3]\n"},
+		{name: "convert csv", stdin: `[{"id": 1, "name": "a, b"}, {"tags": ["x"], "id": 2, "name": null}]`, args: []string{"convert", "--to", "csv"}, code: exitOK,
+			stdout: "id,name,tags\n1,\"a, b\",\n2,,\"[\"\"x\"\"]\"\n"},
+		{name: "convert csv not objects", stdin: `[1]`, args: []string{"convert", "--to", "csv"}, code: exitInvalid, stderr: "element 0 is not an object"},
+		{name: "convert bad format", args: []string{"convert", "--to", "xml"}, code: exitUsage, stderr: `unknown format "xml"`},
+
+		{name: "ingest", stdin: `[{"a": 1}, 2]`, args: []string{"ingest"}, code: exitOK, stdout: "{\"a\":1}\n2\n"},
+		{name: "ingest error", stdin: `[1,`, args: []string{"ingest"}, code: exitInvalid, stderr: "<stdin>: offset 3: unexpected end of input"},
+		{name: "ingest timeout", stdin: `[1]`, args: []string{"ingest", "--timeout", "1ns"}, code: exitInvalid, stderr: "context deadline exceeded"},
+		{name: "diff wrong arity", args: []string{"diff", "a.json"}, code: exitUsage, stderr: "expected 2 files, got 1"},
+		{name: "diff stdin twice", stdin: `{}`, args: []string{"diff", "-", "-"}, code: exitUsage, stderr: "stdin (-) can be read only once"},
+		{name: "patch stdin twice", stdin: `{}`, args: []string{"patch", "--merge", "-", "-"}, code: exitUsage, stderr: "stdin (-) can be read only once"},
+	}
+	for _, tt := range tests {
+		code, stdout, stderr := runCLI(tt.stdin, tt.args...)
+		if code != tt.code {
+			t.Fatalf("%s: exit code %d, want %d\nstdout: %s\nstderr: %s", tt.name, code, tt.code, stdout, stderr)
+		}
+		if tt.stdout != "" && stdout != tt.stdout {
+			t.Fatalf("%s: stdout\n%s\nwant\n%s", tt.name, stdout, tt.stdout)
+		}
+		if !strings.Contains(stderr, tt.stderr) {
+			t.Fatalf("%s: stderr %q does not contain %q", tt.name, stderr, tt.stderr)
+		}
+	}
+}
+
+func TestCLIFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	a := write("a.json", `{"id": 1, "tags": ["x"]}`)
+	b := write("b.json", `{"id": 1, "tags": ["x", "y"]}`)
+	write("c.jsonc", "// comment\n{\"id\": 2}")
+
+	code, stdout, _ := runCLI("", "diff", a, b)
+	if code != exitInvalid || stdout != "+ /tags/1: \"y\"\n" {
+		t.Fatalf("diff: exit %d, output %q", code, stdout)
+	}
+	code, stdout, _ = runCLI("", "diff", "--format", "patch", a, a)
+	if code != exitOK || stdout != "[]\n" {
+		t.Fatalf("diff of equal files: exit %d, output %q", code, stdout)
+	}
+
+	patch := write("patch.json", `[{"op": "replace", "path": "/id", "value": 3}]`)
+	code, stdout, _ = runCLI("", "patch", a, patch)
+	if code != exitOK || stdout != "{\n  \"id\": 3,\n  \"tags\": [\n    \"x\"\n  ]\n}\n" {
+		t.Fatalf("patch: exit %d, output %q", code, stdout)
+	}
+	bad := write("bad.json", `[{"op": "remove", "path": "/nope"}]`)
+	if code, _, stderr := runCLI("", "patch", a, bad); code != exitInvalid || !strings.Contains(stderr, "bad.json") {
+		t.Fatalf("failing patch: exit %d, stderr %q", code, stderr)
+	}
+	merge := write("merge.json", `{"tags": null}`)
+	code, stdout, _ = runCLI("", "patch", "--merge", a, merge)
+	if code != exitOK || stdout != "{\n  \"id\": 1\n}\n" {
+		t.Fatalf("merge patch: exit %d, output %q", code, stdout)
+	}
+
+	// globs expand in name order; the .jsonc file is parsed as JSONC
+	code, stdout, _ = runCLI("", "validate", filepath.Join(dir, "*.json*"))
+	want := strings.Join([]string{a, b, bad, filepath.Join(dir, "c.jsonc"), merge, patch}, ": ok\n") + ": ok\n"
+	if code != exitOK || stdout != want {
+		t.Fatalf("validate glob: exit %d, output\n%s\nwant\n%s", code, stdout, want)
+	}
+
+	code, stdout, stderr := runCLI("", "ingest", "--ordered", "--workers", "2", a, filepath.Join(dir, "missing.json"), b)
+	if code != exitInvalid || stdout != "{\"id\":1,\"tags\":[\"x\"]}\n{\"id\":1,\"tags\":[\"x\",\"y\"]}\n" || !strings.Contains(stderr, "missing.json") {
+		t.Fatalf("ingest: exit %d, output %q, errors %q", code, stdout, stderr)
+	}
+
+	code, stdout, _ = runCLI("", "infer-schema", filepath.Join(dir, "[ab].json"))
+	_, explicit, _ := runCLI("", "infer-schema", a, b)
+	if code != exitOK || stdout != explicit {
+		t.Fatalf("infer-schema glob: exit %d, output\n%s\nwant\n%s", code, stdout, explicit)
+	}
+	if code, _, stderr := runCLI("", "infer-schema", filepath.Join(dir, "*.yaml")); code != exitUsage || !strings.Contains(stderr, "no files match") {
+		t.Fatalf("infer-schema unmatched glob: exit %d, errors %q", code, stderr)
+	}
+
+	unformatted := write("d.json", `{"b":[1,2],"a":{}}`)
+	if code, _, _ := runCLI("", "fmt", "-w", unformatted); code != exitOK {
+		t.Fatalf("fmt -w: exit %d", code)
+	}
+	data, _ := os.ReadFile(unformatted)
+	if string(data) != "{\n  \"b\": [\n    1,\n    2\n  ],\n  \"a\": {}\n}\n" {
+		t.Fatalf("fmt -w wrote %q", data)
+	}
+	if code, stdout, _ := runCLI("", "fmt", "--check", unformatted); code != exitOK || stdout != "" {
+		t.Fatalf("fmt --check after -w: exit %d, output %q", code, stdout)
+	}
+}
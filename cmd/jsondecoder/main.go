@@ -0,0 +1,36 @@
+// Command jsondecoder generates reflection-free JSON decode and encode
+// methods for the struct types of a package that carry the
+// //jsonparser:generate annotation.
+//
+// Usage:
+//
+//	jsondecoder [-dir package-dir] [-o file]
+//
+// It is meant to be run from a go:generate directive in the package itself:
+//
+//	//go:generate go run json-parser/cmd/jsondecoder
+package main
+
+import (
+	"flag"
+	"fmt"
+	"json-parser/codegen"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package to scan")
+	out := flag.String("o", "jsondecode_gen.go", "output file, relative to -dir")
+	flag.Parse()
+
+	src, err := codegen.GenerateDecoders(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(filepath.Join(*dir, *out), src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
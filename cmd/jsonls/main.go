@@ -0,0 +1,16 @@
+// Command jsonls is a Language Server Protocol server for JSON, JSONC and
+// JSON5 files. Editors start it and talk to it over stdin and stdout.
+package main
+
+import (
+	"fmt"
+	"json-parser/lsp"
+	"os"
+)
+
+func main() {
+	if err := lsp.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "jsonls:", err)
+		os.Exit(1)
+	}
+}
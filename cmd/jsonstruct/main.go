@@ -0,0 +1,90 @@
+// Command jsonstruct generates Go struct declarations from sample JSON files.
+//
+// Usage:
+//
+//	jsonstruct [-pkg name] [-o file] [TypeName=]sample.json...
+//
+// Each argument is a sample file, optionally prefixed with the type name to
+// generate for it; otherwise the name is derived from the file name. Repeat a
+// type name to merge several samples into one type. Samples ending in .jsonc
+// or .json5 are read in that dialect, the others as strict JSON. It is meant to be run
+// from a go:generate directive:
+//
+//	//go:generate go run json-parser/cmd/jsonstruct -o models.go User=testdata/users.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"json-parser/codegen"
+	"json-parser/parser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated file (defaults to $GOPACKAGE)")
+	out := flag.String("o", "", "output file (defaults to stdout)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: jsonstruct [-pkg name] [-o file] [TypeName=]sample.json...")
+		os.Exit(2)
+	}
+	if *pkg == "" {
+		*pkg = "main"
+	}
+
+	var inputs []codegen.Input
+	index := make(map[string]int)
+	for _, arg := range flag.Args() {
+		name, path, ok := strings.Cut(arg, "=")
+		if !ok {
+			path = arg
+			base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			name = codegen.TypeName(base)
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		doc, err := parser.ParseDialect(f, dialectOf(path))
+		f.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			os.Exit(1)
+		}
+		if i, seen := index[name]; seen {
+			inputs[i].Samples = append(inputs[i].Samples, doc)
+			continue
+		}
+		index[name] = len(inputs)
+		inputs = append(inputs, codegen.Input{Name: name, Samples: []any{doc}})
+	}
+
+	src, err := codegen.GenerateStructs(*pkg, inputs...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// dialectOf picks the dialect of a sample from its file extension.
+func dialectOf(path string) parser.Dialect {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonc":
+		return parser.JSONC
+	case ".json5":
+		return parser.JSON5
+	}
+	return parser.JSON
+}
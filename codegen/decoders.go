@@ -0,0 +1,405 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Annotation marks a struct type for GenerateDecoders. It goes on its own
+// line in the type's doc comment.
+const Annotation = "//jsonparser:generate"
+
+// GenerateDecoders scans the Go package in dir for struct types annotated
+// with Annotation and emits, for each of them and every struct type they
+// reference, DecodeJSON/DecodeJSONToken methods that drive a parser.Lexer
+// directly and an AppendJSON method that encodes without reflection.
+// Files marked as generated are ignored, so the output can live in dir.
+func GenerateDecoders(dir string) ([]byte, error) {
+	fset := token.NewFileSet()
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	g := &decoderGen{fset: fset, structs: make(map[string]*ast.StructType), queued: make(map[string]bool)}
+	var pkg string
+	var annotated []string
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := goparser.ParseFile(fset, path, nil, goparser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		pkg = file.Name.Name
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				g.structs[ts.Name.Name] = st
+				if hasAnnotation(ts.Doc) || (len(gen.Specs) == 1 && hasAnnotation(gen.Doc)) {
+					annotated = append(annotated, ts.Name.Name)
+				}
+			}
+		}
+	}
+	if len(annotated) == 0 {
+		return nil, fmt.Errorf("no struct types annotated with %s in %s", Annotation, dir)
+	}
+	sort.Strings(annotated)
+	for _, name := range annotated {
+		g.enqueue(name)
+	}
+	for len(g.queue) > 0 {
+		name := g.queue[0]
+		g.queue = g.queue[1:]
+		if err := g.generate(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by jsondecoder. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	for _, imp := range []string{"maps", "slices", "strconv"} {
+		if g.imports[imp] {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+	}
+	out.WriteString("\n\t\"json-parser/parser\"\n)\n\n")
+	out.Write(g.buf.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+func hasAnnotation(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == Annotation {
+			return true
+		}
+	}
+	return false
+}
+
+type decoderGen struct {
+	fset    *token.FileSet
+	structs map[string]*ast.StructType
+	queue   []string
+	queued  map[string]bool
+	imports map[string]bool
+	buf     bytes.Buffer
+	vars    int // counter for unique local variable names
+}
+
+type structField struct {
+	goName    string
+	jsonName  string
+	omitEmpty bool
+	typ       ast.Expr
+}
+
+func (g *decoderGen) enqueue(name string) {
+	if !g.queued[name] {
+		g.queued[name] = true
+		g.queue = append(g.queue, name)
+	}
+}
+
+func (g *decoderGen) use(imp string) {
+	if g.imports == nil {
+		g.imports = make(map[string]bool)
+	}
+	g.imports[imp] = true
+}
+
+func (g *decoderGen) fields(name string) ([]structField, error) {
+	var out []structField
+	for _, f := range g.structs[name].Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded field in %s is not supported", g.fset.Position(f.Pos()), name)
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(f.Tag.Value, "`"))
+		}
+		jsonName, opts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" && opts == "" {
+			continue
+		}
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			sf := structField{goName: n.Name, jsonName: jsonName, typ: f.Type}
+			if sf.jsonName == "" {
+				sf.jsonName = n.Name
+			}
+			for _, o := range strings.Split(opts, ",") {
+				if o == "omitempty" {
+					sf.omitEmpty = true
+				}
+			}
+			if err := g.check(f.Type); err != nil {
+				return nil, fmt.Errorf("%s: field %s.%s: %w", g.fset.Position(n.Pos()), name, n.Name, err)
+			}
+			out = append(out, sf)
+		}
+	}
+	return out, nil
+}
+
+// check rejects field types the generator cannot handle and queues the
+// struct types that need methods of their own.
+func (g *decoderGen) check(t ast.Expr) error {
+	switch x := t.(type) {
+	case *ast.Ident:
+		if _, ok := scalarKinds[x.Name]; ok || x.Name == "any" {
+			return nil
+		}
+		if _, ok := g.structs[x.Name]; ok {
+			g.enqueue(x.Name)
+			return nil
+		}
+	case *ast.InterfaceType:
+		if len(x.Methods.List) == 0 {
+			return nil
+		}
+	case *ast.StarExpr:
+		return g.check(x.X)
+	case *ast.ArrayType:
+		if x.Len == nil {
+			return g.check(x.Elt)
+		}
+	case *ast.MapType:
+		if k, ok := x.Key.(*ast.Ident); ok && k.Name == "string" {
+			return g.check(x.Value)
+		}
+	}
+	return fmt.Errorf("unsupported type %s", types.ExprString(t))
+}
+
+// scalarKinds maps the supported basic types to how they are decoded.
+var scalarKinds = map[string]struct {
+	decode string // parser helper
+	bits   int    // bit size for the int/uint/float helpers
+}{
+	"string":  {"DecodeString", 0},
+	"bool":    {"DecodeBool", 0},
+	"float64": {"DecodeFloat", 64},
+	"float32": {"DecodeFloat", 32},
+	"int":     {"DecodeInt", 0},
+	"int8":    {"DecodeInt", 8},
+	"int16":   {"DecodeInt", 16},
+	"int32":   {"DecodeInt", 32},
+	"int64":   {"DecodeInt", 64},
+	"uint":    {"DecodeUint", 0},
+	"uint8":   {"DecodeUint", 8},
+	"uint16":  {"DecodeUint", 16},
+	"uint32":  {"DecodeUint", 32},
+	"uint64":  {"DecodeUint", 64},
+}
+
+func (g *decoderGen) generate(name string) error {
+	fields, err := g.fields(name)
+	if err != nil {
+		return err
+	}
+	w := &g.buf
+	fmt.Fprintf(w, "// DecodeJSON reads one %s from l.\n", name)
+	fmt.Fprintf(w, "func (v *%s) DecodeJSON(l *parser.Lexer) error {\n", name)
+	w.WriteString("tok, err := l.NextToken()\nif err != nil {\nreturn err\n}\nreturn v.DecodeJSONToken(l, tok)\n}\n\n")
+
+	fmt.Fprintf(w, "// DecodeJSONToken reads one %s whose first token, tok, was already read.\n", name)
+	fmt.Fprintf(w, "func (v *%s) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {\n", name)
+	w.WriteString("return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {\nswitch key {\n")
+	for _, f := range fields {
+		fmt.Fprintf(w, "case %q:\n", f.jsonName)
+		g.decode(w, "v."+f.goName, f.typ)
+	}
+	w.WriteString("default:\nerr = parser.SkipValue(l, tok)\n}\nreturn err\n})\n}\n\n")
+
+	fmt.Fprintf(w, "// AppendJSON appends the JSON encoding of v to buf.\n")
+	fmt.Fprintf(w, "func (v *%s) AppendJSON(buf []byte) []byte {\n", name)
+	w.WriteString("sep := byte('{')\n")
+	for _, f := range fields {
+		if f.omitEmpty {
+			if cond := emptyCheck("v."+f.goName, f.typ); cond != "" {
+				fmt.Fprintf(w, "if !(%s) {\n", cond)
+			}
+		}
+		fmt.Fprintf(w, "buf = append(buf, sep)\nsep = ','\nbuf = append(buf, %q...)\n", fmt.Sprintf("%q:", f.jsonName))
+		g.encode(w, "v."+f.goName, f.typ)
+		if f.omitEmpty && emptyCheck("v."+f.goName, f.typ) != "" {
+			w.WriteString("}\n")
+		}
+	}
+	w.WriteString("if sep == '{' {\nbuf = append(buf, '{')\n}\nreturn append(buf, '}')\n}\n\n")
+	return nil
+}
+
+func (g *decoderGen) local(prefix string) string {
+	g.vars++
+	return fmt.Sprintf("%s%d", prefix, g.vars)
+}
+
+// decode writes statements that decode the value starting at tok into dst,
+// leaving any failure in err.
+func (g *decoderGen) decode(w *bytes.Buffer, dst string, t ast.Expr) {
+	if star, ok := t.(*ast.StarExpr); ok {
+		fmt.Fprintf(w, "if tok.Type == parser.TokenNull {\n%s = nil\n} else {\n", dst)
+		fmt.Fprintf(w, "if %s == nil {\n%s = new(%s)\n}\n", dst, dst, types.ExprString(star.X))
+		g.decodeValue(w, "(*"+dst+")", star.X)
+		w.WriteString("}\n")
+		return
+	}
+	// like encoding/json, null leaves the destination untouched
+	w.WriteString("if tok.Type != parser.TokenNull {\n")
+	g.decodeValue(w, dst, t)
+	w.WriteString("}\n")
+}
+
+func (g *decoderGen) decodeValue(w *bytes.Buffer, dst string, t ast.Expr) {
+	switch x := t.(type) {
+	case *ast.Ident:
+		if x.Name == "any" {
+			fmt.Fprintf(w, "%s, err = parser.DecodeAny(l, tok)\n", dst)
+			return
+		}
+		if k, ok := scalarKinds[x.Name]; ok {
+			switch {
+			case k.decode == "DecodeString" || k.decode == "DecodeBool" || x.Name == "float64":
+				fmt.Fprintf(w, "%s, err = parser.%s(tok)\n", dst, k.decode)
+			case k.decode == "DecodeFloat":
+				n := g.local("f")
+				fmt.Fprintf(w, "var %s float64\n%s, err = parser.DecodeFloat(tok)\n%s = %s(%s)\n", n, n, dst, x.Name, n)
+			default:
+				n := g.local("n")
+				fmt.Fprintf(w, "%s, e := parser.%s(tok, %d)\n%s, err = %s(%s), e\n", n, k.decode, k.bits, dst, x.Name, n)
+			}
+			return
+		}
+		fmt.Fprintf(w, "err = %s.DecodeJSONToken(l, tok)\n", dst)
+	case *ast.InterfaceType:
+		fmt.Fprintf(w, "%s, err = parser.DecodeAny(l, tok)\n", dst)
+	case *ast.ArrayType:
+		elem := g.local("elem")
+		fmt.Fprintf(w, "%s = %s[:0]\nif %s == nil {\n%s = %s{}\n}\n", dst, dst, dst, dst, types.ExprString(x))
+		w.WriteString("err = parser.DecodeArray(l, tok, func(l *parser.Lexer, tok parser.Token) (err error) {\n")
+		fmt.Fprintf(w, "var %s %s\n", elem, types.ExprString(x.Elt))
+		g.decode(w, elem, x.Elt)
+		fmt.Fprintf(w, "%s = append(%s, %s)\nreturn err\n})\n", dst, dst, elem)
+	case *ast.MapType:
+		elem := g.local("elem")
+		fmt.Fprintf(w, "if %s == nil {\n%s = make(%s)\n}\n", dst, dst, types.ExprString(x))
+		w.WriteString("err = parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {\n")
+		fmt.Fprintf(w, "var %s %s\n", elem, types.ExprString(x.Value))
+		g.decode(w, elem, x.Value)
+		fmt.Fprintf(w, "%s[key] = %s\nreturn err\n})\n", dst, elem)
+	}
+}
+
+// encode writes statements appending the encoding of src to buf.
+func (g *decoderGen) encode(w *bytes.Buffer, src string, t ast.Expr) {
+	switch x := t.(type) {
+	case *ast.StarExpr:
+		fmt.Fprintf(w, "if %s == nil {\nbuf = append(buf, \"null\"...)\n} else {\n", src)
+		g.encode(w, "(*"+src+")", x.X)
+		w.WriteString("}\n")
+	case *ast.Ident:
+		k, ok := scalarKinds[x.Name]
+		switch {
+		case x.Name == "any":
+			fmt.Fprintf(w, "buf = parser.AppendAny(buf, %s)\n", src)
+		case !ok:
+			fmt.Fprintf(w, "buf = %s.AppendJSON(buf)\n", src)
+		case k.decode == "DecodeString":
+			fmt.Fprintf(w, "buf = parser.AppendString(buf, %s)\n", src)
+		case k.decode == "DecodeBool":
+			g.use("strconv")
+			fmt.Fprintf(w, "buf = strconv.AppendBool(buf, %s)\n", src)
+		case k.decode == "DecodeFloat":
+			fmt.Fprintf(w, "buf = parser.AppendFloat(buf, float64(%s))\n", src)
+		case k.decode == "DecodeInt":
+			g.use("strconv")
+			fmt.Fprintf(w, "buf = strconv.AppendInt(buf, int64(%s), 10)\n", src)
+		default:
+			g.use("strconv")
+			fmt.Fprintf(w, "buf = strconv.AppendUint(buf, uint64(%s), 10)\n", src)
+		}
+	case *ast.InterfaceType:
+		fmt.Fprintf(w, "buf = parser.AppendAny(buf, %s)\n", src)
+	case *ast.ArrayType:
+		i := g.local("i")
+		fmt.Fprintf(w, "if %s == nil {\nbuf = append(buf, \"null\"...)\n} else {\nbuf = append(buf, '[')\n", src)
+		fmt.Fprintf(w, "for %s := range %s {\nif %s > 0 {\nbuf = append(buf, ',')\n}\n", i, src, i)
+		g.encode(w, fmt.Sprintf("%s[%s]", src, i), x.Elt)
+		w.WriteString("}\nbuf = append(buf, ']')\n}\n")
+	case *ast.MapType:
+		g.use("maps")
+		g.use("slices")
+		i, k := g.local("i"), g.local("k")
+		fmt.Fprintf(w, "if %s == nil {\nbuf = append(buf, \"null\"...)\n} else {\nbuf = append(buf, '{')\n", src)
+		fmt.Fprintf(w, "for %s, %s := range slices.Sorted(maps.Keys(%s)) {\nif %s > 0 {\nbuf = append(buf, ',')\n}\n", i, k, src, i)
+		fmt.Fprintf(w, "buf = parser.AppendString(buf, %s)\nbuf = append(buf, ':')\n", k)
+		if _, isStruct := x.Value.(*ast.Ident); isStruct && g.structs[types.ExprString(x.Value)] != nil {
+			// map values are not addressable, so copy before calling the method
+			v := g.local("v")
+			fmt.Fprintf(w, "%s := %s[%s]\n", v, src, k)
+			g.encode(w, v, x.Value)
+		} else {
+			g.encode(w, fmt.Sprintf("%s[%s]", src, k), x.Value)
+		}
+		w.WriteString("}\nbuf = append(buf, '}')\n}\n")
+	}
+}
+
+// emptyCheck returns the condition under which omitempty drops a field, or
+// "" for types that are never empty (structs).
+func emptyCheck(src string, t ast.Expr) string {
+	switch x := t.(type) {
+	case *ast.StarExpr, *ast.InterfaceType:
+		return src + " == nil"
+	case *ast.ArrayType, *ast.MapType:
+		return "len(" + src + ") == 0"
+	case *ast.Ident:
+		if x.Name == "any" {
+			return src + " == nil"
+		}
+		k, ok := scalarKinds[x.Name]
+		switch {
+		case !ok:
+			return ""
+		case k.decode == "DecodeString":
+			return src + ` == ""`
+		case k.decode == "DecodeBool":
+			return "!" + src
+		default:
+			return src + " == 0"
+		}
+	}
+	return ""
+}
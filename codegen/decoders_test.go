@@ -0,0 +1,51 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateDecodersUpToDate(t *testing.T) {
+	for _, dir := range []string{"internal/decodertest", "../benchmarks"} {
+		t.Run(dir, func(t *testing.T) {
+			src, err := GenerateDecoders(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checked, err := os.ReadFile(filepath.Join(dir, "jsondecode_gen.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(src) != string(checked) {
+				t.Fatalf("%s/jsondecode_gen.go is stale, run go generate", dir)
+			}
+		})
+	}
+}
+
+func TestGenerateDecodersErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"no annotation", "package p\ntype T struct{ A int }\n", "no struct types annotated"},
+		{"unsupported field", "package p\n//jsonparser:generate\ntype T struct{ C chan int }\n", "unsupported type chan int"},
+		{"non-string map key", "package p\n//jsonparser:generate\ntype T struct{ M map[int]string }\n", "unsupported type map[int]string"},
+		{"embedded field", "package p\ntype Base struct{}\n//jsonparser:generate\ntype T struct{ Base }\n", "embedded field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := GenerateDecoders(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+// Code generated by jsondecoder. DO NOT EDIT.
+
+package decodertest
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"json-parser/parser"
+)
+
+// DecodeJSON reads one Record from l.
+func (v *Record) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Record whose first token, tok, was already read.
+func (v *Record) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "name":
+			if tok.Type != parser.TokenNull {
+				v.Name, err = parser.DecodeString(tok)
+			}
+		case "count":
+			if tok.Type != parser.TokenNull {
+				n1, e := parser.DecodeInt(tok, 64)
+				v.Count, err = int64(n1), e
+			}
+		case "small":
+			if tok.Type != parser.TokenNull {
+				n2, e := parser.DecodeInt(tok, 8)
+				v.Small, err = int8(n2), e
+			}
+		case "unsigned":
+			if tok.Type != parser.TokenNull {
+				n3, e := parser.DecodeUint(tok, 16)
+				v.Unsigned, err = uint16(n3), e
+			}
+		case "ratio":
+			if tok.Type != parser.TokenNull {
+				var f4 float64
+				f4, err = parser.DecodeFloat(tok)
+				v.Ratio = float32(f4)
+			}
+		case "score":
+			if tok.Type != parser.TokenNull {
+				v.Score, err = parser.DecodeFloat(tok)
+			}
+		case "active":
+			if tok.Type != parser.TokenNull {
+				v.Active, err = parser.DecodeBool(tok)
+			}
+		case "nickname":
+			if tok.Type == parser.TokenNull {
+				v.Nickname = nil
+			} else {
+				if v.Nickname == nil {
+					v.Nickname = new(string)
+				}
+				(*v.Nickname), err = parser.DecodeString(tok)
+			}
+		case "tags":
+			if tok.Type != parser.TokenNull {
+				v.Tags = v.Tags[:0]
+				if v.Tags == nil {
+					v.Tags = []string{}
+				}
+				err = parser.DecodeArray(l, tok, func(l *parser.Lexer, tok parser.Token) (err error) {
+					var elem5 string
+					if tok.Type != parser.TokenNull {
+						elem5, err = parser.DecodeString(tok)
+					}
+					v.Tags = append(v.Tags, elem5)
+					return err
+				})
+			}
+		case "matrix":
+			if tok.Type != parser.TokenNull {
+				v.Matrix = v.Matrix[:0]
+				if v.Matrix == nil {
+					v.Matrix = [][]int{}
+				}
+				err = parser.DecodeArray(l, tok, func(l *parser.Lexer, tok parser.Token) (err error) {
+					var elem6 []int
+					if tok.Type != parser.TokenNull {
+						elem6 = elem6[:0]
+						if elem6 == nil {
+							elem6 = []int{}
+						}
+						err = parser.DecodeArray(l, tok, func(l *parser.Lexer, tok parser.Token) (err error) {
+							var elem7 int
+							if tok.Type != parser.TokenNull {
+								n8, e := parser.DecodeInt(tok, 0)
+								elem7, err = int(n8), e
+							}
+							elem6 = append(elem6, elem7)
+							return err
+						})
+					}
+					v.Matrix = append(v.Matrix, elem6)
+					return err
+				})
+			}
+		case "labels":
+			if tok.Type != parser.TokenNull {
+				if v.Labels == nil {
+					v.Labels = make(map[string]string)
+				}
+				err = parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+					var elem9 string
+					if tok.Type != parser.TokenNull {
+						elem9, err = parser.DecodeString(tok)
+					}
+					v.Labels[key] = elem9
+					return err
+				})
+			}
+		case "children":
+			if tok.Type != parser.TokenNull {
+				v.Children = v.Children[:0]
+				if v.Children == nil {
+					v.Children = []Child{}
+				}
+				err = parser.DecodeArray(l, tok, func(l *parser.Lexer, tok parser.Token) (err error) {
+					var elem10 Child
+					if tok.Type != parser.TokenNull {
+						err = elem10.DecodeJSONToken(l, tok)
+					}
+					v.Children = append(v.Children, elem10)
+					return err
+				})
+			}
+		case "parent":
+			if tok.Type == parser.TokenNull {
+				v.Parent = nil
+			} else {
+				if v.Parent == nil {
+					v.Parent = new(Child)
+				}
+				err = (*v.Parent).DecodeJSONToken(l, tok)
+			}
+		case "byName":
+			if tok.Type != parser.TokenNull {
+				if v.ByName == nil {
+					v.ByName = make(map[string]Child)
+				}
+				err = parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+					var elem11 Child
+					if tok.Type != parser.TokenNull {
+						err = elem11.DecodeJSONToken(l, tok)
+					}
+					v.ByName[key] = elem11
+					return err
+				})
+			}
+		case "extra":
+			if tok.Type != parser.TokenNull {
+				v.Extra, err = parser.DecodeAny(l, tok)
+			}
+		case "Untagged":
+			if tok.Type != parser.TokenNull {
+				v.Untagged, err = parser.DecodeString(tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Record) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"name\":"...)
+	buf = parser.AppendString(buf, v.Name)
+	if !(v.Count == 0) {
+		buf = append(buf, sep)
+		sep = ','
+		buf = append(buf, "\"count\":"...)
+		buf = strconv.AppendInt(buf, int64(v.Count), 10)
+	}
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"small\":"...)
+	buf = strconv.AppendInt(buf, int64(v.Small), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"unsigned\":"...)
+	buf = strconv.AppendUint(buf, uint64(v.Unsigned), 10)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"ratio\":"...)
+	buf = parser.AppendFloat(buf, float64(v.Ratio))
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"score\":"...)
+	buf = parser.AppendFloat(buf, float64(v.Score))
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"active\":"...)
+	buf = strconv.AppendBool(buf, v.Active)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"nickname\":"...)
+	if v.Nickname == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = parser.AppendString(buf, (*v.Nickname))
+	}
+	if !(len(v.Tags) == 0) {
+		buf = append(buf, sep)
+		sep = ','
+		buf = append(buf, "\"tags\":"...)
+		if v.Tags == nil {
+			buf = append(buf, "null"...)
+		} else {
+			buf = append(buf, '[')
+			for i12 := range v.Tags {
+				if i12 > 0 {
+					buf = append(buf, ',')
+				}
+				buf = parser.AppendString(buf, v.Tags[i12])
+			}
+			buf = append(buf, ']')
+		}
+	}
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"matrix\":"...)
+	if v.Matrix == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '[')
+		for i13 := range v.Matrix {
+			if i13 > 0 {
+				buf = append(buf, ',')
+			}
+			if v.Matrix[i13] == nil {
+				buf = append(buf, "null"...)
+			} else {
+				buf = append(buf, '[')
+				for i14 := range v.Matrix[i13] {
+					if i14 > 0 {
+						buf = append(buf, ',')
+					}
+					buf = strconv.AppendInt(buf, int64(v.Matrix[i13][i14]), 10)
+				}
+				buf = append(buf, ']')
+			}
+		}
+		buf = append(buf, ']')
+	}
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"labels\":"...)
+	if v.Labels == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '{')
+		for i15, k16 := range slices.Sorted(maps.Keys(v.Labels)) {
+			if i15 > 0 {
+				buf = append(buf, ',')
+			}
+			buf = parser.AppendString(buf, k16)
+			buf = append(buf, ':')
+			buf = parser.AppendString(buf, v.Labels[k16])
+		}
+		buf = append(buf, '}')
+	}
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"children\":"...)
+	if v.Children == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '[')
+		for i17 := range v.Children {
+			if i17 > 0 {
+				buf = append(buf, ',')
+			}
+			buf = v.Children[i17].AppendJSON(buf)
+		}
+		buf = append(buf, ']')
+	}
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"parent\":"...)
+	if v.Parent == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = (*v.Parent).AppendJSON(buf)
+	}
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"byName\":"...)
+	if v.ByName == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '{')
+		for i18, k19 := range slices.Sorted(maps.Keys(v.ByName)) {
+			if i18 > 0 {
+				buf = append(buf, ',')
+			}
+			buf = parser.AppendString(buf, k19)
+			buf = append(buf, ':')
+			v20 := v.ByName[k19]
+			buf = v20.AppendJSON(buf)
+		}
+		buf = append(buf, '}')
+	}
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"extra\":"...)
+	buf = parser.AppendAny(buf, v.Extra)
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"Untagged\":"...)
+	buf = parser.AppendString(buf, v.Untagged)
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+// DecodeJSON reads one Child from l.
+func (v *Child) DecodeJSON(l *parser.Lexer) error {
+	tok, err := l.NextToken()
+	if err != nil {
+		return err
+	}
+	return v.DecodeJSONToken(l, tok)
+}
+
+// DecodeJSONToken reads one Child whose first token, tok, was already read.
+func (v *Child) DecodeJSONToken(l *parser.Lexer, tok parser.Token) error {
+	return parser.DecodeObject(l, tok, func(l *parser.Lexer, key string, tok parser.Token) (err error) {
+		switch key {
+		case "id":
+			if tok.Type != parser.TokenNull {
+				n21, e := parser.DecodeInt(tok, 0)
+				v.ID, err = int(n21), e
+			}
+		case "note":
+			if tok.Type == parser.TokenNull {
+				v.Note = nil
+			} else {
+				if v.Note == nil {
+					v.Note = new(string)
+				}
+				(*v.Note), err = parser.DecodeString(tok)
+			}
+		default:
+			err = parser.SkipValue(l, tok)
+		}
+		return err
+	})
+}
+
+// AppendJSON appends the JSON encoding of v to buf.
+func (v *Child) AppendJSON(buf []byte) []byte {
+	sep := byte('{')
+	buf = append(buf, sep)
+	sep = ','
+	buf = append(buf, "\"id\":"...)
+	buf = strconv.AppendInt(buf, int64(v.ID), 10)
+	if !(v.Note == nil) {
+		buf = append(buf, sep)
+		sep = ','
+		buf = append(buf, "\"note\":"...)
+		if v.Note == nil {
+			buf = append(buf, "null"...)
+		} else {
+			buf = parser.AppendString(buf, (*v.Note))
+		}
+	}
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
@@ -0,0 +1,32 @@
+// Package decodertest holds types covering every field kind the decoder
+// generator supports, with the generated methods checked in next to them.
+package decodertest
+
+//go:generate go run json-parser/cmd/jsondecoder
+
+//jsonparser:generate
+type Record struct {
+	Name     string            `json:"name"`
+	Count    int64             `json:"count,omitempty"`
+	Small    int8              `json:"small"`
+	Unsigned uint16            `json:"unsigned"`
+	Ratio    float32           `json:"ratio"`
+	Score    float64           `json:"score"`
+	Active   bool              `json:"active"`
+	Nickname *string           `json:"nickname"`
+	Tags     []string          `json:"tags,omitempty"`
+	Matrix   [][]int           `json:"matrix"`
+	Labels   map[string]string `json:"labels"`
+	Children []Child           `json:"children"`
+	Parent   *Child            `json:"parent"`
+	ByName   map[string]Child  `json:"byName"`
+	Extra    any               `json:"extra"`
+	Skipped  string            `json:"-"`
+	Untagged string
+	hidden   string
+}
+
+type Child struct {
+	ID   int     `json:"id"`
+	Note *string `json:"note,omitempty"`
+}
@@ -0,0 +1,95 @@
+package decodertest
+
+import (
+	"encoding/json"
+	"json-parser/parser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `{
+	"name": "widget",
+	"count": 12,
+	"small": -3,
+	"unsigned": 65535,
+	"ratio": 0.5,
+	"score": 1e3,
+	"active": true,
+	"nickname": "w",
+	"tags": ["a", "b"],
+	"matrix": [[1, 2], [], [3]],
+	"labels": {"env": "prod", "tier": "1"},
+	"children": [{"id": 1, "note": "first"}, {"id": 2}],
+	"parent": {"id": 0},
+	"byName": {"x": {"id": 7}},
+	"extra": {"nested": [true, null, "s", 1.5]},
+	"unknown": {"deep": [1, {"a": "b"}]},
+	"Skipped": "ignored",
+	"Untagged": "kept"
+}`
+
+func TestDecodeMatchesEncodingJSON(t *testing.T) {
+	var got Record
+	if err := got.DecodeJSON(parser.NewLexer(strings.NewReader(sample))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var want Record
+	if err := json.Unmarshal([]byte(sample), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mismatch:\nexpected %+v\ngot      %+v", want, got)
+	}
+}
+
+func TestAppendJSONRoundTrip(t *testing.T) {
+	var r Record
+	if err := r.DecodeJSON(parser.NewLexer(strings.NewReader(sample))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := r.AppendJSON(nil)
+	var back Record
+	if err := json.Unmarshal(out, &back); err != nil {
+		t.Fatalf("generated encoding is not valid JSON: %v\n%s", err, out)
+	}
+	if !reflect.DeepEqual(r, back) {
+		t.Fatalf("round trip mismatch:\nexpected %+v\ngot      %+v", r, back)
+	}
+}
+
+func TestAppendJSONOmitEmpty(t *testing.T) {
+	out := string((&Child{ID: 3}).AppendJSON(nil))
+	if out != `{"id":3}` {
+		t.Fatalf("unexpected encoding %s", out)
+	}
+	out = string((&Record{}).AppendJSON(nil))
+	if strings.Contains(out, `"count"`) || strings.Contains(out, `"tags"`) {
+		t.Fatalf("omitempty fields were written: %s", out)
+	}
+}
+
+func TestDecodeNullKeepsValues(t *testing.T) {
+	r := Record{Name: "keep", Nickname: new(string)}
+	if err := r.DecodeJSON(parser.NewLexer(strings.NewReader(`{"name":null,"nickname":null}`))); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.Name != "keep" || r.Nickname != nil {
+		t.Fatalf("unexpected result %+v", r)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, input := range []string{
+		`{"name": 1}`,
+		`{"small": 300}`,
+		`{"tags": ["a" "b"]}`,
+		`{"children": [{"id": "x"}]}`,
+		`[1]`,
+	} {
+		var r Record
+		if err := r.DecodeJSON(parser.NewLexer(strings.NewReader(input))); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Package codegen generates Go source from JSON samples and Go types.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"json-parser/schema"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Input is one family of sample documents and the Go type name to give it.
+// When the samples are arrays of objects the name is used for the element
+// struct.
+type Input struct {
+	Name    string
+	Samples []any
+}
+
+// GenerateStructs emits a gofmt'ed Go file in package pkg declaring a struct
+// for every input, with nested named types for sub-objects. Struct shapes
+// are merged across all samples of an input: members missing from some
+// objects get omitempty and members that were null become pointers.
+func GenerateStructs(pkg string, inputs ...Input) ([]byte, error) {
+	g := &structGen{names: make(map[string]string)}
+	for _, in := range inputs {
+		s := schema.Infer(schema.InferOptions{MaxEnumValues: -1}, in.Samples...)
+		if types, _ := typeList(s); len(types) == 1 && types[0] == "array" {
+			if items, ok := s["items"].(map[string]any); ok {
+				s = items
+			}
+		}
+		name := exportedName(in.Name)
+		if t := g.goType(s, name, ""); strings.TrimPrefix(t, "*") != name {
+			// not an object; still give the caller the named type they asked for
+			g.decls = append(g.decls, fmt.Sprintf("type %s %s\n", name, t))
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by jsonstruct. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	for _, d := range g.decls {
+		buf.WriteString(d)
+		buf.WriteByte('\n')
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+type structGen struct {
+	decls []string
+	names map[string]string // type name -> struct body, to reuse identical types
+}
+
+// goType returns the Go type for an inferred schema, declaring named structs
+// as needed. name is the preferred type name, parent the enclosing type's.
+func (g *structGen) goType(s map[string]any, name, parent string) string {
+	types, nullable := typeList(s)
+	if len(types) != 1 {
+		return "any"
+	}
+	var t string
+	switch types[0] {
+	case "integer":
+		t = "int"
+	case "number":
+		t = "float64"
+	case "string":
+		t = "string"
+	case "boolean":
+		t = "bool"
+	case "array":
+		items, ok := s["items"].(map[string]any)
+		if !ok {
+			return "[]any"
+		}
+		return "[]" + g.goType(items, singular(name), parent)
+	case "object":
+		props, _ := s["properties"].(map[string]any)
+		if len(props) == 0 {
+			return "map[string]any"
+		}
+		t = g.declareStruct(s, props, name, parent)
+	default:
+		return "any"
+	}
+	if nullable {
+		return "*" + t
+	}
+	return t
+}
+
+func (g *structGen) declareStruct(s map[string]any, props map[string]any, name, parent string) string {
+	required := make(map[string]bool)
+	if req, ok := s["required"].([]any); ok {
+		for _, r := range req {
+			required[r.(string)] = true
+		}
+	}
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// nested types are declared while the fields are built; remember where
+	// this struct started so it can be placed ahead of them
+	at := len(g.decls)
+	var body strings.Builder
+	body.WriteString("struct {\n")
+	used := make(map[string]bool)
+	for _, k := range keys {
+		field := exportedName(k)
+		for used[field] {
+			field += "_"
+		}
+		used[field] = true
+		ft := g.goType(props[k].(map[string]any), field, name)
+		tag := k
+		if !required[k] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&body, "\t%s %s `json:%q`\n", field, ft, tag)
+	}
+	body.WriteString("}")
+
+	return g.claim(name, parent, body.String(), at)
+}
+
+// claim picks a type name for a struct body, reusing an existing declaration
+// with an identical body and disambiguating clashes with the parent's name.
+func (g *structGen) claim(name, parent, body string, at int) string {
+	candidates := []string{name, parent + name}
+	for i := 2; ; i++ {
+		for _, c := range candidates {
+			existing, taken := g.names[c]
+			if taken && existing == body {
+				return c
+			}
+			if !taken {
+				g.names[c] = body
+				g.decls = slices.Insert(g.decls, at, fmt.Sprintf("type %s %s\n", c, body))
+				return c
+			}
+		}
+		candidates = []string{fmt.Sprintf("%s%s%d", parent, name, i)}
+	}
+}
+
+// typeList returns the non-null types of an inferred schema and whether null
+// was allowed.
+func typeList(s map[string]any) ([]string, bool) {
+	var names []string
+	switch t := s["type"].(type) {
+	case string:
+		names = []string{t}
+	case []any:
+		for _, n := range t {
+			names = append(names, n.(string))
+		}
+	}
+	var out []string
+	nullable := false
+	for _, n := range names {
+		if n == "null" {
+			nullable = true
+			continue
+		}
+		out = append(out, n)
+	}
+	return out, nullable
+}
+
+// commonInitialisms are kept upper case in generated identifiers, as golint
+// expects.
+var commonInitialisms = map[string]bool{
+	"API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"SQL": true, "TCP": true, "TLS": true, "TTL": true, "UI": true, "URI": true,
+	"URL": true, "UTF8": true, "UUID": true, "XML": true,
+}
+
+// exportedName converts a JSON member name such as "userId" or "catch_phrase"
+// into an exported Go identifier ("UserID", "CatchPhrase").
+func exportedName(s string) string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if up := strings.ToUpper(w); commonInitialisms[up] {
+			b.WriteString(up)
+			continue
+		}
+		r := []rune(w)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// singular makes a rough guess at the element name for an array member,
+// e.g. "Tags" -> "Tag".
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "ss"):
+		return name
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return name[:len(name)-1]
+	default:
+		return name + "Item"
+	}
+}
+
+// TypeName derives a struct name from a plural noun such as a file name:
+// "example_users" becomes "ExampleUser".
+func TypeName(s string) string {
+	name := exportedName(s)
+	if strings.HasSuffix(name, "s") {
+		return singular(name)
+	}
+	return name
+}
@@ -0,0 +1,116 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	jsonparser "json-parser/parser"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parse(input string) any {
+	return jsonparser.BasicParase(strings.NewReader(input))
+}
+
+func TestGenerateStructs(t *testing.T) {
+	src, err := GenerateStructs("models", Input{Name: "Order", Samples: []any{parse(`[
+		{"id":1,"total":9.5,"note":null,"tags":["a"],"customer":{"name":"x","address":{"city":"y"}},"items":[{"sku":"a","qty":1}]},
+		{"id":2,"total":3,"note":"rush","tags":[],"customer":{"name":"z","address":{"city":"w"}},"items":[],"coupon":"SAVE"}
+	]`)}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "// Code generated by jsonstruct. DO NOT EDIT.\n\npackage models\n\n" +
+		"type Order struct {\n" +
+		"\tCoupon   string   `json:\"coupon,omitempty\"`\n" +
+		"\tCustomer Customer `json:\"customer\"`\n" +
+		"\tID       int      `json:\"id\"`\n" +
+		"\tItems    []Item   `json:\"items\"`\n" +
+		"\tNote     *string  `json:\"note\"`\n" +
+		"\tTags     []string `json:\"tags\"`\n" +
+		"\tTotal    float64  `json:\"total\"`\n" +
+		"}\n\n" +
+		"type Customer struct {\n" +
+		"\tAddress Address `json:\"address\"`\n" +
+		"\tName    string  `json:\"name\"`\n" +
+		"}\n\n" +
+		"type Address struct {\n" +
+		"\tCity string `json:\"city\"`\n" +
+		"}\n\n" +
+		"type Item struct {\n" +
+		"\tQty int    `json:\"qty\"`\n" +
+		"\tSku string `json:\"sku\"`\n" +
+		"}\n"
+	if string(src) != expected {
+		t.Errorf("generated code mismatch:\nwant\n%s\ngot\n%s", expected, src)
+	}
+}
+
+func TestGenerateStructsNameClash(t *testing.T) {
+	src, err := GenerateStructs("models",
+		Input{Name: "Shipment", Samples: []any{parse(`{"address":{"city":"a"}}`)}},
+		Input{Name: "Invoice", Samples: []any{parse(`{"address":{"street":"b"},"billing":{"address":{"city":"c"}}}`)}},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	code := string(src)
+	for _, want := range []string{
+		"type Address struct {\n\tCity string",
+		"type InvoiceAddress struct {\n\tStreet string",
+		"type Billing struct {\n\tAddress Address `json:\"address\"`",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("expected generated code to contain %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	cases := map[string]string{
+		"userId":       "UserID",
+		"catchPhrase":  "CatchPhrase",
+		"bs":           "Bs",
+		"zip_code":     "ZipCode",
+		"avatar-url":   "AvatarURL",
+		"HTTPServer":   "HTTPServer",
+		"2fa":          "X2fa",
+		"":             "Field",
+		"example_user": "ExampleUser",
+	}
+	for in, want := range cases {
+		if got := exportedName(in); got != want {
+			t.Errorf("exportedName(%q): want %q got %q", in, want, got)
+		}
+	}
+}
+
+func TestGenerateStructsTestData(t *testing.T) {
+	var inputs []Input
+	for _, name := range []string{"albums", "posts", "todos", "users"} {
+		f, err := os.Open("../test_data/example_" + name + ".json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, Input{Name: TypeName(name), Samples: []any{jsonparser.BasicParase(f)}})
+		f.Close()
+	}
+	src, err := GenerateStructs("models", inputs...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "models.go", src, 0)
+	if err != nil {
+		t.Fatalf("generated code does not parse: %v", err)
+	}
+	var names []string
+	for name := range file.Scope.Objects {
+		names = append(names, name)
+	}
+	for _, want := range []string{"Album", "Post", "Todo", "User", "Address", "Geo", "Company"} {
+		if file.Scope.Lookup(want) == nil {
+			t.Errorf("missing type %s, have %v", want, names)
+		}
+	}
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"json-parser/parser"
+	"json-parser/schema"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var validateCommand = &command{
+	name:    "validate",
+	args:    "[file...]",
+	summary: "report every syntax error, and schema violations with --schema",
+	run:     runValidate,
+}
+
+// problem is a diagnostic or schema violation found by validate.
+type problem struct {
+	offset   int
+	severity string
+	message  string
+}
+
+func runValidate(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	schemaFile := fs.String("schema", "", "also validate against the JSON Schema in `file`")
+	jsonOut := fs.Bool("json", false, "print a JSON report instead of text")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	inputs, err := c.readInputs(args, *dialect)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	var sch *schema.Schema
+	if *schemaFile != "" {
+		if sch, err = schema.Load(*schemaFile); err != nil {
+			return c.usageError(fs, "%v", err)
+		}
+	}
+	report := make([]any, 0, len(inputs))
+	for _, in := range inputs {
+		problems, valid := validate(in, sch)
+		if !valid {
+			code = exitInvalid
+		}
+		if *jsonOut {
+			report = append(report, validateReport(in, problems, valid))
+			continue
+		}
+		for _, p := range problems {
+			fmt.Fprintf(c.stdout, "%s: %s: %s\n", in.location(p.offset), p.severity, p.message)
+		}
+		if valid {
+			fmt.Fprintf(c.stdout, "%s: ok\n", in.name)
+		}
+	}
+	if *jsonOut {
+		c.writeIndented(report)
+	}
+	return code
+}
+
+// validate checks the syntax of in and, when it has no syntax errors and sch
+// is set, its conformance to the schema. Warnings do not make a document
+// invalid.
+func validate(in input, sch *schema.Schema) ([]problem, bool) {
+	_, diags, _ := parser.ParseRecover(bytes.NewReader(in.data), in.dialect)
+	valid := true
+	var problems []problem
+	for _, d := range diags {
+		if d.Severity == parser.SeverityError {
+			valid = false
+		}
+		problems = append(problems, problem{offset: d.Start, severity: d.Severity.String(), message: d.Message})
+	}
+	if !valid || sch == nil {
+		return problems, valid
+	}
+	v, positions, err := parser.ParsePositions(bytes.NewReader(in.data), in.dialect)
+	if err != nil {
+		return append(problems, problem{severity: "error", message: err.Error()}), false
+	}
+	var verrs schema.ValidationErrors
+	if err := sch.Validate(v); !errors.As(err, &verrs) {
+		return problems, true
+	}
+	for _, e := range verrs {
+		span, _ := positions.Value(e.InstanceLocation)
+		msg := fmt.Sprintf("%s (schema %s)", e.Message, e.KeywordLocation)
+		problems = append(problems, problem{offset: span.Start.Offset, severity: "error", message: msg})
+	}
+	return problems, false
+}
+
+func validateReport(in input, problems []problem, valid bool) map[string]any {
+	diags := make([]any, 0, len(problems))
+	for _, p := range problems {
+		line, col := in.lineCol(p.offset)
+		diags = append(diags, map[string]any{
+			"line":     line,
+			"column":   col,
+			"severity": p.severity,
+			"message":  p.message,
+		})
+	}
+	return map[string]any{"file": in.name, "valid": valid, "diagnostics": diags}
+}
+
+var fmtCommand = &command{
+	name:    "fmt",
+	args:    "[file...]",
+	summary: "pretty-print keeping member order and comments",
+	run:     runFmt,
+}
+
+func runFmt(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	indent := fs.Int("indent", 2, "spaces per indentation level")
+	tabs := fs.Bool("tabs", false, "indent with tabs")
+	write := fs.Bool("w", false, "write the result back to the files instead of stdout")
+	check := fs.Bool("check", false, "list files that are not formatted and exit 1 if there are any")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if *indent < 0 {
+		return c.usageError(fs, "negative --indent %d", *indent)
+	}
+	inputs, err := c.readInputs(args, *dialect)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	unit := strings.Repeat(" ", *indent)
+	if *tabs {
+		unit = "\t"
+	}
+	for _, in := range inputs {
+		if *write && in.name == stdinName {
+			return c.usageError(fs, "cannot write back to stdin")
+		}
+		tree, err := in.parseCST()
+		if err != nil {
+			code = c.fail(in, err)
+			continue
+		}
+		out := tree.Format(unit)
+		switch {
+		case *check:
+			if !bytes.Equal(out, in.data) {
+				fmt.Fprintln(c.stdout, in.name)
+				code = exitInvalid
+			}
+		case *write:
+			if bytes.Equal(out, in.data) {
+				continue
+			}
+			if err := os.WriteFile(in.name, out, 0o644); err != nil {
+				fmt.Fprintln(c.stderr, err)
+				return exitUsage
+			}
+		default:
+			c.stdout.Write(out)
+		}
+	}
+	return code
+}
+
+var minifyCommand = &command{
+	name:    "minify",
+	args:    "[file...]",
+	summary: "strip whitespace, comments and trailing commas",
+	run:     runMinify,
+}
+
+func runMinify(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	inputs, err := c.readInputs(args, *dialect)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	for _, in := range inputs {
+		tree, err := in.parseCST()
+		if err != nil {
+			code = c.fail(in, err)
+			continue
+		}
+		fmt.Fprintf(c.stdout, "%s\n", tree.Compact())
+	}
+	return code
+}
+
+var queryCommand = &command{
+	name:    "query",
+	args:    "<pointer> [file...]",
+	summary: "print the value at a JSON Pointer",
+	run:     runQuery,
+}
+
+func runQuery(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	raw := fs.Bool("raw", false, "print strings without quotes")
+	compact := fs.Bool("compact", false, "print on one line")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(args) == 0 {
+		return c.usageError(fs, "missing pointer")
+	}
+	ptr, err := parser.ParsePointer(args[0])
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	inputs, err := c.readInputs(args[1:], *dialect)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	for _, in := range inputs {
+		doc, err := in.parse()
+		if err != nil {
+			code = c.fail(in, err)
+			continue
+		}
+		v, err := ptr.Get(doc)
+		if err != nil {
+			code = c.fail(in, err)
+			continue
+		}
+		switch s, isString := v.(string); {
+		case *raw && isString:
+			fmt.Fprintln(c.stdout, s)
+		case *compact:
+			code = max(code, c.writeJSON(v))
+		default:
+			code = max(code, c.writeIndented(v))
+		}
+	}
+	return code
+}
+
+var tokensCommand = &command{
+	name:    "tokens",
+	args:    "[file...]",
+	summary: "print the token stream with line and column",
+	run:     runTokens,
+}
+
+func runTokens(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	jsonOut := fs.Bool("json", false, "print a JSON array per file")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	inputs, err := c.readInputs(args, *dialect)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	for _, in := range inputs {
+		l := parser.NewLexerDialect(bytes.NewReader(in.data), in.dialect)
+		toks := []any{}
+		for {
+			tok, err := l.NextToken()
+			if err != nil {
+				start, _ := l.Span()
+				fmt.Fprintf(c.stderr, "%s: %v\n", in.location(start), err)
+				code = exitInvalid
+				break
+			}
+			if tok.Type == parser.TokenEOF {
+				break
+			}
+			if *jsonOut {
+				toks = append(toks, map[string]any{
+					"type":   tok.Type.String(),
+					"value":  tok.Value,
+					"line":   tok.Start.Line,
+					"column": tok.Start.Column,
+				})
+				continue
+			}
+			value := tok.Value
+			if tok.Type == parser.TokenString {
+				value = strconv.Quote(value)
+			}
+			fmt.Fprintf(c.stdout, "%d:%d\t%v\t%s\n", tok.Start.Line, tok.Start.Column, tok.Type, value)
+		}
+		if *jsonOut {
+			c.writeJSON(toks)
+		}
+	}
+	return code
+}
+
+var statsCommand = &command{
+	name:    "stats",
+	args:    "[file...]",
+	summary: "count values by kind and measure nesting depth",
+	run:     runStats,
+}
+
+// stats describes the shape of a document.
+type stats struct {
+	objects, arrays, strings, numbers, booleans, nulls, keys int
+	depth                                                    int // nesting depth of the deepest value
+}
+
+func (s *stats) add(v any, depth int) {
+	s.depth = max(s.depth, depth)
+	switch v := v.(type) {
+	case map[string]any:
+		s.objects++
+		s.keys += len(v)
+		for _, item := range v {
+			s.add(item, depth+1)
+		}
+	case []any:
+		s.arrays++
+		for _, item := range v {
+			s.add(item, depth+1)
+		}
+	case string:
+		s.strings++
+	case float64:
+		s.numbers++
+	case bool:
+		s.booleans++
+	case nil:
+		s.nulls++
+	}
+}
+
+func runStats(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	jsonOut := fs.Bool("json", false, "print a JSON report instead of text")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	inputs, err := c.readInputs(args, *dialect)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	report := make([]any, 0, len(inputs))
+	for _, in := range inputs {
+		doc, err := in.parse()
+		if err != nil {
+			code = c.fail(in, err)
+			continue
+		}
+		var s stats
+		s.add(doc, 1)
+		if *jsonOut {
+			report = append(report, map[string]any{
+				"file":     in.name,
+				"bytes":    len(in.data),
+				"depth":    s.depth,
+				"objects":  s.objects,
+				"keys":     s.keys,
+				"arrays":   s.arrays,
+				"strings":  s.strings,
+				"numbers":  s.numbers,
+				"booleans": s.booleans,
+				"nulls":    s.nulls,
+			})
+			continue
+		}
+		fmt.Fprintf(c.stdout, "%s: %d bytes, depth %d, %d objects (%d keys), %d arrays, %d strings, %d numbers, %d booleans, %d nulls\n",
+			in.name, len(in.data), s.depth, s.objects, s.keys, s.arrays, s.strings, s.numbers, s.booleans, s.nulls)
+	}
+	if *jsonOut {
+		c.writeIndented(report)
+	}
+	return code
+}
+
+var convertCommand = &command{
+	name:    "convert",
+	args:    "--to json|jsonl|csv [file...]",
+	summary: "convert JSONC or JSON5 to JSON, or an array to JSON Lines or CSV",
+	run:     runConvert,
+}
+
+func runConvert(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	to := fs.String("to", "json", "output `format`: json, jsonl (one line per array element) or csv (array of objects)")
+	indent := fs.Int("indent", 2, "spaces per indentation level for --to json; 0 for compact output")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	var convert func(tree *parser.CST) ([]byte, error)
+	switch *to {
+	case "json":
+		unit := strings.Repeat(" ", max(*indent, 0))
+		convert = func(tree *parser.CST) ([]byte, error) {
+			out, err := tree.JSON(unit)
+			return append(out, '\n'), err
+		}
+	case "jsonl":
+		convert = toJSONLines
+	case "csv":
+		convert = toCSV
+	default:
+		return c.usageError(fs, "unknown format %q", *to)
+	}
+	inputs, err := c.readInputs(args, *dialect)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	for _, in := range inputs {
+		tree, err := in.parseCST()
+		if err == nil {
+			var out []byte
+			if out, err = convert(tree); err == nil {
+				c.stdout.Write(out)
+				continue
+			}
+		}
+		code = c.fail(in, err)
+	}
+	return code
+}
+
+// elementJSON converts one element of a document to compact JSON, keeping
+// the member order of the source.
+func elementJSON(n *parser.Node) ([]byte, error) {
+	return (&parser.CST{Root: n}).JSON("")
+}
+
+func toJSONLines(tree *parser.CST) ([]byte, error) {
+	if tree.Root.Kind != parser.NodeArray {
+		return nil, errors.New("jsonl output needs a top-level array")
+	}
+	var out []byte
+	for _, e := range tree.Root.Elements {
+		line, err := elementJSON(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		out = append(append(out, line...), '\n')
+	}
+	return out, nil
+}
+
+// toCSV writes an array of objects as CSV. The header lists every key in the
+// order it is first seen; strings are written as is, null and missing
+// members as empty cells and other values as compact JSON.
+func toCSV(tree *parser.CST) ([]byte, error) {
+	if tree.Root.Kind != parser.NodeArray {
+		return nil, errors.New("csv output needs a top-level array of objects")
+	}
+	var header []string
+	column := map[string]int{}
+	for i, e := range tree.Root.Elements {
+		if e.Value.Kind != parser.NodeObject {
+			return nil, fmt.Errorf("csv output needs a top-level array of objects, element %d is not an object", i)
+		}
+		for _, m := range e.Value.Members {
+			if _, ok := column[m.Key.Value]; !ok {
+				column[m.Key.Value] = len(header)
+				header = append(header, m.Key.Value)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(header)
+	for _, e := range tree.Root.Elements {
+		record := make([]string, len(header))
+		for _, m := range e.Value.Members {
+			cell, err := csvCell(m.Value)
+			if err != nil {
+				return nil, err
+			}
+			record[column[m.Key.Value]] = cell
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func csvCell(n *parser.Node) (string, error) {
+	if n.Kind == parser.NodeScalar {
+		switch n.Open.Type {
+		case parser.TokenString:
+			return n.Open.Value, nil
+		case parser.TokenNull:
+			return "", nil
+		}
+	}
+	out, err := elementJSON(n)
+	return string(out), err
+}
+
+// parseCST reads an input into a concrete syntax tree.
+func (in input) parseCST() (*parser.CST, error) {
+	return parser.ParseCST(bytes.NewReader(in.data), in.dialect)
+}
@@ -0,0 +1,499 @@
+// Package diff compares two documents produced by the parser and reports the
+// paths that were added, removed or changed between them.
+package diff
+
+import (
+	"fmt"
+	"json-parser/parser"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ArrayMode selects how array elements of the two documents are paired up.
+type ArrayMode int
+
+const (
+	ByIndex ArrayMode = iota // element i is compared with element i
+	ByLCS                    // align equal elements with a longest common subsequence
+	ByKey                    // pair objects sharing the same Options.Key member, in any order
+)
+
+// Options controls a comparison. The zero value compares arrays by index and
+// numbers exactly.
+type Options struct {
+	Arrays ArrayMode
+	// Key names the identity member used by ByKey, e.g. "id". Elements that
+	// are not objects or lack the member are paired by value instead. Keys
+	// and such values are compared exactly, without Tolerance.
+	Key string
+	// Tolerance is the largest absolute difference at which two numbers are
+	// still considered equal.
+	Tolerance float64
+}
+
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Changed
+	Moved // only reported by ByKey
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	case Moved:
+		return "moved"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// Change is one difference between the documents. Path is a JSON Pointer into
+// the old document for removed and changed values and into the new document
+// for added and moved ones.
+type Change struct {
+	Kind ChangeKind
+	Path string
+	From string // the path in the old document of a Moved value
+	Old  any    // unset for Added and Moved
+	New  any    // unset for Removed and Moved
+}
+
+// Result holds the outcome of Compare.
+type Result struct {
+	Changes []Change
+	// Patch is an RFC 6902 patch that turns the old document into the new one
+	// following the same alignment as Changes.
+	Patch []parser.Operation
+}
+
+// Equal reports whether no differences were found.
+func (r *Result) Equal() bool { return len(r.Changes) == 0 }
+
+// Compare diffs a against b.
+func Compare(a, b any, opts Options) *Result {
+	d := differ{opts: opts, res: &Result{}}
+	d.walk(parser.Pointer{}, parser.Pointer{}, parser.Pointer{}, a, b)
+	return d.res
+}
+
+type differ struct {
+	opts Options
+	res  *Result
+}
+
+// walk compares a and b. oldPath locates a in the old document and newPath b
+// in the new one; patchPath locates a in the document as it looks while the
+// patch is being applied.
+func (d *differ) walk(oldPath, newPath, patchPath parser.Pointer, a, b any) {
+	switch x := a.(type) {
+	case map[string]any:
+		if y, ok := b.(map[string]any); ok {
+			d.walkObject(oldPath, newPath, patchPath, x, y)
+			return
+		}
+	case []any:
+		if y, ok := b.([]any); ok {
+			d.walkArray(oldPath, newPath, patchPath, x, y)
+			return
+		}
+	}
+	if !d.equal(a, b) {
+		d.res.Changes = append(d.res.Changes, Change{Kind: Changed, Path: oldPath.String(), Old: a, New: b})
+		d.res.Patch = append(d.res.Patch, parser.Operation{Op: "replace", Path: patchPath.String(), Value: b})
+	}
+}
+
+func (d *differ) walkObject(oldPath, newPath, patchPath parser.Pointer, a, b map[string]any) {
+	for _, k := range unionKeys(a, b) {
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case inA && inB:
+			d.walk(oldPath.Append(k), newPath.Append(k), patchPath.Append(k), av, bv)
+		case inA:
+			d.res.Changes = append(d.res.Changes, Change{Kind: Removed, Path: oldPath.Append(k).String(), Old: av})
+			d.res.Patch = append(d.res.Patch, parser.Operation{Op: "remove", Path: patchPath.Append(k).String()})
+		default:
+			d.res.Changes = append(d.res.Changes, Change{Kind: Added, Path: newPath.Append(k).String(), New: bv})
+			d.res.Patch = append(d.res.Patch, parser.Operation{Op: "add", Path: patchPath.Append(k).String(), Value: bv})
+		}
+	}
+}
+
+// step is one entry of an array alignment: a pair of indexes, or -1 on the
+// side the element is missing from.
+type step struct{ i, j int }
+
+func (d *differ) walkArray(oldPath, newPath, patchPath parser.Pointer, a, b []any) {
+	var steps []step
+	switch d.opts.Arrays {
+	case ByLCS:
+		steps = align(a, b, d.equal)
+	case ByKey:
+		d.walkKeyed(oldPath, newPath, patchPath, a, b)
+		return
+	default:
+		steps = alignByIndex(len(a), len(b))
+	}
+	// k tracks the element's index in the partially patched array
+	k := 0
+	for _, s := range steps {
+		at := patchPath.Append(strconv.Itoa(k))
+		switch {
+		case s.i >= 0 && s.j >= 0:
+			d.walk(oldPath.Append(strconv.Itoa(s.i)), newPath.Append(strconv.Itoa(s.j)), at, a[s.i], b[s.j])
+			k++
+		case s.i >= 0:
+			d.res.Changes = append(d.res.Changes, Change{Kind: Removed, Path: oldPath.Append(strconv.Itoa(s.i)).String(), Old: a[s.i]})
+			d.res.Patch = append(d.res.Patch, parser.Operation{Op: "remove", Path: at.String()})
+		default:
+			d.res.Changes = append(d.res.Changes, Change{Kind: Added, Path: newPath.Append(strconv.Itoa(s.j)).String(), New: b[s.j]})
+			d.res.Patch = append(d.res.Patch, parser.Operation{Op: "add", Path: at.String(), Value: b[s.j]})
+			k++
+		}
+	}
+}
+
+func alignByIndex(n, m int) []step {
+	steps := make([]step, 0, max(n, m))
+	for i := 0; i < min(n, m); i++ {
+		steps = append(steps, step{i, i})
+	}
+	for i := m; i < n; i++ {
+		steps = append(steps, step{i, -1})
+	}
+	for j := n; j < m; j++ {
+		steps = append(steps, step{-1, j})
+	}
+	return steps
+}
+
+// align pairs the elements of a and b along a longest common subsequence of
+// elements accepted by match. Unmatched elements that fall in the same gap
+// are paired positionally so they are diffed rather than replaced.
+func align(a, b []any, match func(x, y any) bool) []step {
+	// common prefix and suffix keep the quadratic table small for the usual
+	// case of a few edits in a long array
+	pre := 0
+	for pre < len(a) && pre < len(b) && match(a[pre], b[pre]) {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && match(a[len(a)-1-suf], b[len(b)-1-suf]) {
+		suf++
+	}
+	midA, midB := a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	n, m := len(midA), len(midB)
+	table := make([][]int, n+1)
+	for i := range table {
+		table[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if match(midA[i], midB[j]) {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	steps := make([]step, 0, len(a)+len(b))
+	for i := 0; i < pre; i++ {
+		steps = append(steps, step{i, i})
+	}
+	var gapA, gapB []int
+	flush := func() {
+		paired := min(len(gapA), len(gapB))
+		for x := 0; x < paired; x++ {
+			steps = append(steps, step{gapA[x], gapB[x]})
+		}
+		for _, i := range gapA[paired:] {
+			steps = append(steps, step{i, -1})
+		}
+		for _, j := range gapB[paired:] {
+			steps = append(steps, step{-1, j})
+		}
+		gapA, gapB = gapA[:0], gapB[:0]
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && match(midA[i], midB[j]):
+			flush()
+			steps = append(steps, step{pre + i, pre + j})
+			i++
+			j++
+		case j >= m || (i < n && table[i+1][j] >= table[i][j+1]):
+			gapA = append(gapA, pre+i)
+			i++
+		default:
+			gapB = append(gapB, pre+j)
+			j++
+		}
+	}
+	flush()
+	for x := 0; x < suf; x++ {
+		steps = append(steps, step{len(a) - suf + x, len(b) - suf + x})
+	}
+	return steps
+}
+
+// walkKeyed pairs the elements of a and b by identity and recurses into the
+// pairs. Paired elements keep the longest run that is already in order in
+// place; the others are reported as Moved and moved into place by the
+// patch, those going further back by way of the end of the array.
+func (d *differ) walkKeyed(oldPath, newPath, patchPath parser.Pointer, a, b []any) {
+	match := d.pairByKey(a, b)
+	paired := make([]bool, len(a))
+	var order []int // indexes in a of the paired elements, in the order of b
+	for _, i := range match {
+		if i >= 0 {
+			paired[i] = true
+			order = append(order, i)
+		}
+	}
+	removed := 0
+	for i, ok := range paired {
+		if ok {
+			continue
+		}
+		d.res.Changes = append(d.res.Changes, Change{Kind: Removed, Path: oldPath.Append(strconv.Itoa(i)).String(), Old: a[i]})
+		d.res.Patch = append(d.res.Patch, parser.Operation{Op: "remove", Path: patchPath.Append(strconv.Itoa(i - removed)).String()})
+		removed++
+	}
+
+	stay := longestIncreasing(order, len(a))
+	// rest holds the indexes in a of the elements after the j already in
+	// place, in the order the partially patched array has them
+	rest := make([]int, 0, len(order))
+	for i, ok := range paired {
+		if ok {
+			rest = append(rest, i)
+		}
+	}
+	for j, i := range match {
+		at := patchPath.Append(strconv.Itoa(j))
+		if i < 0 {
+			d.res.Changes = append(d.res.Changes, Change{Kind: Added, Path: newPath.Append(strconv.Itoa(j)).String(), New: b[j]})
+			d.res.Patch = append(d.res.Patch, parser.Operation{Op: "add", Path: at.String(), Value: b[j]})
+			continue
+		}
+		if stay[i] {
+			// elements in front of it belong further back; set them aside
+			for rest[0] != i {
+				rest = append(rest[1:], rest[0])
+				d.res.Patch = append(d.res.Patch, parser.Operation{Op: "move", From: at.String(), Path: patchPath.Append("-").String()})
+			}
+		} else {
+			d.res.Changes = append(d.res.Changes, Change{Kind: Moved, Path: newPath.Append(strconv.Itoa(j)).String(), From: oldPath.Append(strconv.Itoa(i)).String()})
+			if p := slices.Index(rest, i); p > 0 {
+				rest = append(rest[:p], rest[p+1:]...)
+				rest = append([]int{i}, rest...)
+				d.res.Patch = append(d.res.Patch, parser.Operation{Op: "move", From: patchPath.Append(strconv.Itoa(j + p)).String(), Path: at.String()})
+			}
+		}
+		rest = rest[1:]
+		d.walk(oldPath.Append(strconv.Itoa(i)), newPath.Append(strconv.Itoa(j)), at, a[i], b[j])
+	}
+}
+
+// pairByKey returns for every element of b the index of the element of a
+// with the same identity, or -1. Elements sharing an identity are paired in
+// order.
+func (d *differ) pairByKey(a, b []any) []int {
+	byID := make(map[string][]int, len(a))
+	for i, x := range a {
+		id := d.identity(x)
+		byID[id] = append(byID[id], i)
+	}
+	match := make([]int, len(b))
+	for j, y := range b {
+		id := d.identity(y)
+		if is := byID[id]; len(is) > 0 {
+			match[j], byID[id] = is[0], is[1:]
+		} else {
+			match[j] = -1
+		}
+	}
+	return match
+}
+
+// identity is the Options.Key member of an object, or the whole value of
+// other elements, in a canonical encoding.
+func (d *differ) identity(v any) string {
+	if o, ok := v.(map[string]any); ok {
+		if k, ok := o[d.opts.Key]; ok {
+			return "k" + compact(k)
+		}
+	}
+	return "v" + compact(v)
+}
+
+// longestIncreasing marks, in a slice of n, the values making up a longest
+// increasing subsequence of seq, a sequence of distinct values below n.
+func longestIncreasing(seq []int, n int) []bool {
+	// tails[k] is the position in seq of the smallest value ending an
+	// increasing subsequence of length k+1; prev links the subsequences
+	var tails []int
+	prev := make([]int, len(seq))
+	for p, v := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		prev[p] = -1
+		if k > 0 {
+			prev[p] = tails[k-1]
+		}
+		if k == len(tails) {
+			tails = append(tails, p)
+		} else {
+			tails[k] = p
+		}
+	}
+	in := make([]bool, n)
+	if len(tails) > 0 {
+		for p := tails[len(tails)-1]; p >= 0; p = prev[p] {
+			in[seq[p]] = true
+		}
+	}
+	return in
+}
+
+// equal is parser.Equal with the numeric tolerance applied.
+func (d *differ) equal(a, b any) bool {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		return ok && math.Abs(x-y) <= d.opts.Tolerance
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !d.equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !d.equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return parser.Equal(a, b)
+	}
+}
+
+// unionKeys returns the member names of both objects in sorted order.
+func unionKeys(a, b map[string]any) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+)
+
+// Text renders the changes one per line, prefixed with +, -, ~ or >. When color
+// is set the lines are wrapped in ANSI color codes.
+func (r *Result) Text(color bool) string {
+	var b strings.Builder
+	for _, c := range r.Changes {
+		var line, col string
+		switch c.Kind {
+		case Added:
+			line, col = fmt.Sprintf("+ %s: %s", displayPath(c.Path), compact(c.New)), colorGreen
+		case Removed:
+			line, col = fmt.Sprintf("- %s: %s", displayPath(c.Path), compact(c.Old)), colorRed
+		case Moved:
+			line, col = fmt.Sprintf("> %s: moved from %s", displayPath(c.Path), c.From), colorCyan
+		default:
+			line, col = fmt.Sprintf("~ %s: %s -> %s", displayPath(c.Path), compact(c.Old), compact(c.New)), colorYellow
+		}
+		if color {
+			line = col + line + colorReset
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func displayPath(p string) string {
+	if p == "" {
+		return "(root)"
+	}
+	return p
+}
+
+func compact(v any) string {
+	out, err := parser.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(out)
+}
+
+// Report returns a machine-readable summary in the parser's value
+// representation, ready to be passed to parser.Marshal.
+func (r *Result) Report() map[string]any {
+	counts := map[ChangeKind]float64{}
+	changes := make([]any, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		counts[c.Kind]++
+		entry := map[string]any{"type": c.Kind.String(), "path": c.Path}
+		switch c.Kind {
+		case Added:
+			entry["new"] = c.New
+		case Removed:
+			entry["old"] = c.Old
+		case Moved:
+			entry["from"] = c.From
+		default:
+			entry["old"], entry["new"] = c.Old, c.New
+		}
+		changes = append(changes, entry)
+	}
+	summary := map[string]any{
+		"added":   counts[Added],
+		"removed": counts[Removed],
+		"changed": counts[Changed],
+	}
+	if counts[Moved] > 0 {
+		summary["moved"] = counts[Moved]
+	}
+	return map[string]any{"equal": r.Equal(), "summary": summary, "changes": changes}
+}
@@ -0,0 +1,173 @@
+package diff
+
+import (
+	"json-parser/parser"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parse(input string) any {
+	return parser.BasicParase(strings.NewReader(input))
+}
+
+func checkPatch(t *testing.T, a, b any, res *Result) {
+	t.Helper()
+	got, err := parser.ApplyPatch(a, res.Patch)
+	if err != nil {
+		t.Fatalf("generated patch failed to apply: %v", err)
+	}
+	if !parser.Equal(got, b) {
+		t.Fatalf("patch round trip mismatch:\nexpected %#v\ngot      %#v", b, got)
+	}
+}
+
+func TestCompareObjects(t *testing.T) {
+	a := parse(`{"name":"Leanne","address":{"city":"Gwenborough","zip":"1"},"phone":"x"}`)
+	b := parse(`{"name":"Leanne","address":{"city":"Wisokyburgh","zip":"1"},"website":"y"}`)
+	res := Compare(a, b, Options{})
+
+	expected := []Change{
+		{Kind: Changed, Path: "/address/city", Old: "Gwenborough", New: "Wisokyburgh"},
+		{Kind: Removed, Path: "/phone", Old: "x"},
+		{Kind: Added, Path: "/website", New: "y"},
+	}
+	if len(res.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %+v", len(expected), res.Changes)
+	}
+	for i, c := range res.Changes {
+		if c != expected[i] {
+			t.Errorf("change %d: want %+v got %+v", i, expected[i], c)
+		}
+	}
+	checkPatch(t, a, b, res)
+}
+
+func TestCompareArrayModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  ArrayMode
+		a, b  string
+		kinds []ChangeKind
+		paths []string
+	}{
+		{"index insert shifts everything", ByIndex, `[1,2,3]`, `[0,1,2,3]`,
+			[]ChangeKind{Changed, Changed, Changed, Added}, []string{"/0", "/1", "/2", "/3"}},
+		{"lcs insert", ByLCS, `[1,2,3]`, `[0,1,2,3]`,
+			[]ChangeKind{Added}, []string{"/0"}},
+		{"lcs remove", ByLCS, `["a","b","c","d"]`, `["a","c","d"]`,
+			[]ChangeKind{Removed}, []string{"/1"}},
+		{"lcs pairs gaps", ByLCS, `[1,{"x":1},3]`, `[1,{"x":2},3]`,
+			[]ChangeKind{Changed}, []string{"/1/x"}},
+		{"key insert and edit", ByKey, `[{"id":1,"t":"a"},{"id":2,"t":"b"}]`, `[{"id":0,"t":"z"},{"id":1,"t":"a"},{"id":2,"t":"c"}]`,
+			[]ChangeKind{Added, Changed}, []string{"/0", "/1/t"}},
+		{"key insert before added member", ByKey, `[{"id":1}]`, `[{"id":0},{"id":1,"n":2}]`,
+			[]ChangeKind{Added, Added}, []string{"/0", "/1/n"}},
+		{"lcs insert before added member", ByLCS, `[1,{"a":1}]`, `[0,1,{"a":1,"b":2}]`,
+			[]ChangeKind{Added, Added}, []string{"/0", "/2/b"}},
+		{"key replaced record", ByKey, `[{"id":1,"t":"a"}]`, `[{"id":2,"t":"a"}]`,
+			[]ChangeKind{Removed, Added}, []string{"/0", "/0"}},
+		{"key swap", ByKey, `[{"id":1},{"id":2}]`, `[{"id":2},{"id":1}]`,
+			[]ChangeKind{Moved}, []string{"/0"}},
+		{"key rotate left", ByKey, `[{"id":1},{"id":2},{"id":3},{"id":4}]`, `[{"id":2},{"id":3},{"id":4},{"id":1}]`,
+			[]ChangeKind{Moved}, []string{"/3"}},
+		{"key rotate right", ByKey, `[{"id":1},{"id":2},{"id":3},{"id":4}]`, `[{"id":4},{"id":1},{"id":2},{"id":3}]`,
+			[]ChangeKind{Moved}, []string{"/0"}},
+		{"key move and edit", ByKey, `[{"id":1,"t":"a"},{"id":2},{"id":3}]`, `[{"id":3},{"id":2},{"id":1,"t":"b"}]`,
+			[]ChangeKind{Moved, Moved, Changed}, []string{"/0", "/1", "/0/t"}},
+		{"key move, remove and add", ByKey, `[{"id":1},{"id":2},{"id":3},{"id":4}]`, `[{"id":5},{"id":3},{"id":1},{"id":2}]`,
+			[]ChangeKind{Removed, Added, Moved}, []string{"/3", "/0", "/1"}},
+		{"key reverse", ByKey, `[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5}]`, `[{"id":5},{"id":4},{"id":3},{"id":2},{"id":1}]`,
+			[]ChangeKind{Moved, Moved, Moved, Moved}, []string{"/0", "/1", "/2", "/3"}},
+		{"key duplicates", ByKey, `[{"id":1,"n":1},{"id":2},{"id":1,"n":2}]`, `[{"id":1,"n":1},{"id":1,"n":2},{"id":2}]`,
+			[]ChangeKind{Moved}, []string{"/1"}},
+		{"key values without member", ByKey, `[1,"a",{"x":1}]`, `[{"x":1},1,"a"]`,
+			[]ChangeKind{Moved}, []string{"/0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := parse(tt.a), parse(tt.b)
+			res := Compare(a, b, Options{Arrays: tt.mode, Key: "id"})
+			if len(res.Changes) != len(tt.kinds) {
+				t.Fatalf("expected %d changes, got %+v", len(tt.kinds), res.Changes)
+			}
+			for i, c := range res.Changes {
+				if c.Kind != tt.kinds[i] || c.Path != tt.paths[i] {
+					t.Errorf("change %d: want %v %s got %v %s", i, tt.kinds[i], tt.paths[i], c.Kind, c.Path)
+				}
+			}
+			checkPatch(t, a, b, res)
+		})
+	}
+}
+
+func TestCompareKeyedMove(t *testing.T) {
+	a, b := parse(`[{"id":1},{"id":2}]`), parse(`[{"id":2},{"id":1}]`)
+	res := Compare(a, b, Options{Arrays: ByKey, Key: "id"})
+	if len(res.Patch) != 1 || res.Patch[0].Op != "move" || res.Patch[0].From != "/1" || res.Patch[0].Path != "/0" {
+		t.Fatalf("want a single move from /1 to /0, got %+v", res.Patch)
+	}
+	if c := res.Changes[0]; c.From != "/1" {
+		t.Fatalf("want the move reported from /1, got %+v", c)
+	}
+	if got := res.Text(false); got != "> /0: moved from /1\n" {
+		t.Fatalf("unexpected text %q", got)
+	}
+}
+
+func TestCompareTolerance(t *testing.T) {
+	a, b := parse(`{"lat":-37.3159,"lng":81.1496}`), parse(`{"lat":-37.31591,"lng":81.2}`)
+	res := Compare(a, b, Options{Tolerance: 0.001})
+	if len(res.Changes) != 1 || res.Changes[0].Path != "/lng" {
+		t.Fatalf("expected only /lng to change, got %+v", res.Changes)
+	}
+	if res := Compare(a, a, Options{}); !res.Equal() {
+		t.Fatalf("expected identical documents to be equal, got %+v", res.Changes)
+	}
+}
+
+func TestCompareTestData(t *testing.T) {
+	f, err := os.Open("../test_data/example_todos.json")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+	a := parser.BasicParase(f)
+
+	// drop one record, edit one title and append a new record
+	b := parser.DeepCopy(a).([]any)
+	b = append(b[:4], b[5:]...)
+	b[10].(map[string]any)["title"] = "edited"
+	b = append(b, map[string]any{"userId": 10.0, "id": 201.0, "title": "new", "completed": false})
+
+	for _, mode := range []ArrayMode{ByIndex, ByLCS, ByKey} {
+		res := Compare(a, b, Options{Arrays: mode, Key: "id"})
+		checkPatch(t, a, b, res)
+		if mode == ByKey && len(res.Changes) != 3 {
+			t.Errorf("expected 3 keyed changes, got %d: %s", len(res.Changes), res.Text(false))
+		}
+	}
+}
+
+func TestResultOutput(t *testing.T) {
+	res := Compare(parse(`{"a":1,"b":[true]}`), parse(`{"a":2,"c":null}`), Options{})
+
+	text := res.Text(false)
+	expected := "~ /a: 1 -> 2\n- /b: [true]\n+ /c: null\n"
+	if text != expected {
+		t.Errorf("text mismatch:\nwant %q\ngot  %q", expected, text)
+	}
+	if colored := res.Text(true); !strings.Contains(colored, colorGreen+"+ /c: null"+colorReset) {
+		t.Errorf("expected colored added line, got %q", colored)
+	}
+
+	report, err := parser.Marshal(res.Report())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"changes":[{"new":2,"old":1,"path":"/a","type":"changed"},{"old":[true],"path":"/b","type":"removed"},{"new":null,"path":"/c","type":"added"}],"equal":false,"summary":{"added":1,"changed":1,"removed":1}}`
+	if string(report) != want {
+		t.Errorf("report mismatch:\nwant %s\ngot  %s", want, report)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"json-parser/parser"
+	"json-parser/pipeline"
+	"os"
+)
+
+var ingestCommand = &command{
+	name:    "ingest",
+	args:    "[file...]",
+	summary: "load many files concurrently and print their records as JSON Lines",
+	run:     runIngest,
+}
+
+func runIngest(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	workers := fs.Int("workers", 0, "files parsed at the same time; 0 for one per CPU")
+	ordered := fs.Bool("ordered", false, "print records in file order instead of as they are ready")
+	timeout := fs.Duration("timeout", 0, "give up after this long; 0 for no limit")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	paths, err := expandArgs(args)
+	if err != nil {
+		return c.usageError(fs, "%v", err)
+	}
+	sources := make([]pipeline.Source, len(paths))
+	for i, path := range paths {
+		sources[i] = c.ingestSource(path)
+	}
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	opts := pipeline.Options{Workers: *workers, Ordered: *ordered, Parse: consumeData}
+	records, errs := pipeline.Run[any](ctx, sources, nil, opts)
+	for records != nil || errs != nil {
+		select {
+		case rec, ok := <-records:
+			if !ok {
+				records = nil
+				continue
+			}
+			code = max(code, c.writeJSON(rec.Value))
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			fmt.Fprintln(c.stderr, err)
+			code = exitInvalid
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		fmt.Fprintf(c.stderr, "json-parser ingest: %v\n", err)
+		return exitInvalid
+	}
+	return code
+}
+
+// ingestSource opens an export file, or stdin for "-".
+func (c *cli) ingestSource(path string) pipeline.Source {
+	if path == "-" {
+		return pipeline.Source{Name: stdinName, Open: func() (io.ReadCloser, error) {
+			return io.NopCloser(c.stdin), nil
+		}}
+	}
+	return pipeline.Source{Name: path, Open: func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}}
+}
+
+// consumeData parses one export file in the dialect of its extension,
+// stopping when ctx is done.
+func consumeData(ctx context.Context, name string, r io.Reader) (any, error) {
+	d, err := dialectOf(name, "auto")
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseDialectContext(ctx, r, d)
+}
@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"json-parser/parser"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is an open text document.
+type document struct {
+	uri     string
+	text    string
+	dialect parser.Dialect
+	lines   []int // offsets of line starts
+}
+
+func newDocument(uri, languageID, text string) *document {
+	d := &document{uri: uri, dialect: dialectFor(uri, languageID)}
+	d.setText(text)
+	return d
+}
+
+func (d *document) setText(text string) {
+	d.text = text
+	d.lines = []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+}
+
+// dialectFor picks the dialect from the language id the client sent, falling
+// back to the file extension.
+func dialectFor(uri, languageID string) parser.Dialect {
+	switch languageID {
+	case "jsonc":
+		return parser.JSONC
+	case "json5":
+		return parser.JSON5
+	}
+	switch strings.ToLower(filepath.Ext(uri)) {
+	case ".jsonc":
+		return parser.JSONC
+	case ".json5":
+		return parser.JSON5
+	}
+	return parser.JSON
+}
+
+// uriPath returns the file system path of a file:// URI, or "" for other
+// schemes.
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func pathURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// position converts a byte offset into an LSP position: zero-based line and
+// character counted in UTF-16 code units.
+func (d *document) position(offset int) map[string]any {
+	offset = min(max(offset, 0), len(d.text))
+	line := sort.SearchInts(d.lines, offset+1) - 1
+	char := 0
+	for _, r := range d.text[d.lines[line]:offset] {
+		char++
+		if r > 0xffff {
+			char++
+		}
+	}
+	return map[string]any{"line": line, "character": char}
+}
+
+// offset converts an LSP position into a byte offset, clamping positions
+// past the end of a line or of the document.
+func (d *document) offset(pos map[string]any) int {
+	line, char := intField(pos, "line"), intField(pos, "character")
+	if line < 0 {
+		return 0
+	}
+	if line >= len(d.lines) {
+		return len(d.text)
+	}
+	i := d.lines[line]
+	for units := 0; i < len(d.text) && d.text[i] != '\n' && units < char; {
+		r, size := utf8.DecodeRuneInString(d.text[i:])
+		i += size
+		units++
+		if r > 0xffff {
+			units++
+		}
+	}
+	return i
+}
+
+func (d *document) rangeOf(start, end int) map[string]any {
+	return map[string]any{"start": d.position(start), "end": d.position(end)}
+}
+
+// tokenRange converts token positions from the Lexer directly.
+func tokenRange(start, end parser.Position) map[string]any {
+	return map[string]any{
+		"start": map[string]any{"line": start.Line - 1, "character": start.UTF16Column - 1},
+		"end":   map[string]any{"line": end.Line - 1, "character": end.UTF16Column - 1},
+	}
+}
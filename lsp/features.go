@@ -0,0 +1,328 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"json-parser/parser"
+	"json-parser/schema"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LSP enumerations used below.
+const (
+	severityError   = 1
+	severityWarning = 2
+
+	symbolString  = 15
+	symbolNumber  = 16
+	symbolBoolean = 17
+	symbolArray   = 18
+	symbolObject  = 19
+	symbolNull    = 21
+)
+
+// diagnostics reports syntax errors and, for documents without any, the
+// violations of the schema named by a top-level "$schema" member.
+func (s *Server) diagnostics(doc *document) []any {
+	_, diags, _ := parser.ParseRecover(strings.NewReader(doc.text), doc.dialect)
+	out := []any{}
+	for _, d := range diags {
+		severity := severityError
+		if d.Severity == parser.SeverityWarning {
+			severity = severityWarning
+		}
+		out = append(out, map[string]any{
+			"range":    doc.rangeOf(d.Start, d.End),
+			"severity": severity,
+			"source":   "jsonls",
+			"message":  d.Message,
+		})
+	}
+	if len(diags) > 0 {
+		return out
+	}
+	return append(out, s.schemaDiagnostics(doc)...)
+}
+
+func (s *Server) schemaDiagnostics(doc *document) []any {
+	v, positions, err := parser.ParsePositions(strings.NewReader(doc.text), doc.dialect)
+	if err != nil {
+		return nil
+	}
+	obj, _ := v.(map[string]any)
+	ref, ok := obj["$schema"].(string)
+	if !ok {
+		return nil
+	}
+	path := schemaPath(doc, ref)
+	if path == "" {
+		// remote schemas are not fetched
+		return nil
+	}
+	diagnostic := func(pointer, message string, severity int) map[string]any {
+		span, _ := positions.Value(pointer)
+		return map[string]any{
+			"range":    tokenRange(span.Start, span.End),
+			"severity": severity,
+			"source":   "jsonls",
+			"message":  message,
+		}
+	}
+	sch, err := schema.Load(path)
+	if err != nil {
+		return []any{diagnostic("/$schema", "cannot load schema: "+err.Error(), severityWarning)}
+	}
+	var verrs schema.ValidationErrors
+	if err := sch.Validate(v); !errors.As(err, &verrs) {
+		return nil
+	}
+	out := make([]any, 0, len(verrs))
+	for _, e := range verrs {
+		out = append(out, diagnostic(e.InstanceLocation, fmt.Sprintf("%s (schema %s)", e.Message, e.KeywordLocation), severityError))
+	}
+	return out
+}
+
+// schemaPath resolves a "$schema" reference to a local file, relative to the
+// document. It returns "" for references to other schemes such as https.
+func schemaPath(doc *document, ref string) string {
+	if strings.HasPrefix(ref, "file:") {
+		return uriPath(ref)
+	}
+	if u, err := url.Parse(ref); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		return ""
+	}
+	if filepath.IsAbs(ref) {
+		return ref
+	}
+	dir := filepath.Dir(uriPath(doc.uri))
+	return filepath.Join(dir, filepath.FromSlash(ref))
+}
+
+// symbols returns the outline of the document: one symbol per object member
+// or array element, nested like the document.
+func (s *Server) symbols(doc *document, _ map[string]any) any {
+	tree, err := parser.ParseCST(strings.NewReader(doc.text), doc.dialect)
+	if err != nil {
+		return []any{}
+	}
+	return childSymbols(tree.Root)
+}
+
+func childSymbols(n *parser.Node) []any {
+	out := []any{}
+	for _, m := range n.Members {
+		out = append(out, symbol(m.Key.Value, m.Key, m.Value))
+	}
+	for i, e := range n.Elements {
+		out = append(out, symbol(strconv.Itoa(i), e.Value.Open, e.Value))
+	}
+	return out
+}
+
+func symbol(name string, first *parser.CSTToken, value *parser.Node) map[string]any {
+	last := lastToken(value)
+	sym := map[string]any{
+		"name":           name,
+		"kind":           symbolKind(value),
+		"range":          tokenRange(first.Start, last.End),
+		"selectionRange": tokenRange(first.Start, first.End),
+	}
+	if value.Kind == parser.NodeScalar {
+		sym["detail"] = value.Open.Raw
+	} else {
+		sym["children"] = childSymbols(value)
+	}
+	return sym
+}
+
+func symbolKind(n *parser.Node) int {
+	switch n.Kind {
+	case parser.NodeObject:
+		return symbolObject
+	case parser.NodeArray:
+		return symbolArray
+	}
+	switch n.Open.Type {
+	case parser.TokenString:
+		return symbolString
+	case parser.TokenTrue, parser.TokenFalse:
+		return symbolBoolean
+	case parser.TokenNull:
+		return symbolNull
+	}
+	return symbolNumber
+}
+
+func kindName(n *parser.Node) string {
+	switch symbolKind(n) {
+	case symbolObject:
+		return "object"
+	case symbolArray:
+		return "array"
+	case symbolString:
+		return "string"
+	case symbolBoolean:
+		return "boolean"
+	case symbolNull:
+		return "null"
+	}
+	return "number"
+}
+
+func lastToken(n *parser.Node) *parser.CSTToken {
+	if n.Close != nil {
+		return n.Close
+	}
+	return n.Open
+}
+
+// target is what the cursor is on: a value, or the key of a member.
+type target struct {
+	pointer parser.Pointer
+	node    *parser.Node
+	key     *parser.CSTToken // set when the cursor is on a key
+	parent  *parser.Member   // the member whose value node is, if any
+}
+
+// find returns the innermost key or value containing offset.
+func find(n *parser.Node, offset int, at parser.Pointer) (target, bool) {
+	if offset < n.Open.Start.Offset || offset >= lastToken(n).End.Offset {
+		return target{}, false
+	}
+	for _, m := range n.Members {
+		member := at.Append(m.Key.Value)
+		if offset >= m.Key.Start.Offset && offset < m.Key.End.Offset {
+			return target{pointer: member, node: m.Value, key: m.Key, parent: m}, true
+		}
+		if t, ok := find(m.Value, offset, member); ok {
+			if t.node == m.Value && t.key == nil {
+				t.parent = m
+			}
+			return t, true
+		}
+	}
+	for i, e := range n.Elements {
+		if t, ok := find(e.Value, offset, at.Append(strconv.Itoa(i))); ok {
+			return t, true
+		}
+	}
+	return target{pointer: at, node: n}, true
+}
+
+// hover shows the JSON Pointer of the key or value under the cursor.
+func (s *Server) hover(doc *document, params map[string]any) any {
+	tree, err := parser.ParseCST(strings.NewReader(doc.text), doc.dialect)
+	if err != nil {
+		return nil
+	}
+	t, ok := find(tree.Root, doc.offset(object(params, "position")), parser.Pointer{})
+	if !ok {
+		return nil
+	}
+	pointer := t.pointer.String()
+	if pointer == "" {
+		pointer = "/"
+	}
+	first, last := t.node.Open, lastToken(t.node)
+	if t.key != nil {
+		first, last = t.key, t.key
+	}
+	return map[string]any{
+		"contents": map[string]any{"kind": "markdown", "value": fmt.Sprintf("`%s` (%s)", pointer, kindName(t.node))},
+		"range":    tokenRange(first.Start, last.End),
+	}
+}
+
+// format re-indents the whole document, keeping member order and comments.
+func (s *Server) format(doc *document, params map[string]any) any {
+	tree, err := parser.ParseCST(strings.NewReader(doc.text), doc.dialect)
+	if err != nil {
+		return nil
+	}
+	options := object(params, "options")
+	indent := "\t"
+	if spaces, _ := options["insertSpaces"].(bool); spaces {
+		indent = strings.Repeat(" ", max(intField(options, "tabSize"), 1))
+	}
+	formatted := string(tree.Format(indent))
+	if formatted == doc.text {
+		return []any{}
+	}
+	return []any{map[string]any{"range": doc.rangeOf(0, len(doc.text)), "newText": formatted}}
+}
+
+// foldingRanges returns one range per object or array spanning several
+// lines. The line of the closing bracket stays visible.
+func (s *Server) foldingRanges(doc *document, _ map[string]any) any {
+	tree, err := parser.ParseCST(strings.NewReader(doc.text), doc.dialect)
+	if err != nil {
+		return []any{}
+	}
+	out := []any{}
+	var walk func(n *parser.Node)
+	walk = func(n *parser.Node) {
+		if n.Kind == parser.NodeScalar {
+			return
+		}
+		if start, end := n.Open.Start.Line-1, n.Close.Start.Line-2; end > start {
+			out = append(out, map[string]any{"startLine": start, "endLine": end})
+		}
+		for _, m := range n.Members {
+			walk(m.Value)
+		}
+		for _, e := range n.Elements {
+			walk(e.Value)
+		}
+	}
+	walk(tree.Root)
+	return out
+}
+
+// definition jumps from a "$ref" value to the schema location it names,
+// either in the same document or in a file relative to it.
+func (s *Server) definition(doc *document, params map[string]any) any {
+	tree, err := parser.ParseCST(strings.NewReader(doc.text), doc.dialect)
+	if err != nil {
+		return nil
+	}
+	t, ok := find(tree.Root, doc.offset(object(params, "position")), parser.Pointer{})
+	if !ok || t.key != nil || t.parent == nil || t.parent.Key.Value != "$ref" || t.node.Open.Type != parser.TokenString {
+		return nil
+	}
+	file, fragment, _ := strings.Cut(t.node.Open.Value, "#")
+	pointer, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil
+	}
+	uri, text := doc.uri, doc.text
+	if file != "" {
+		path := schemaPath(doc, file)
+		if path == "" {
+			return nil
+		}
+		uri = pathURI(path)
+		if open, ok := s.docs[uri]; ok {
+			text = open.text
+		} else {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			text = string(data)
+		}
+	}
+	_, positions, err := parser.ParsePositions(strings.NewReader(text), dialectFor(uri, ""))
+	if err != nil {
+		return nil
+	}
+	span, ok := positions.Value(pointer)
+	if !ok {
+		return nil
+	}
+	return map[string]any{"uri": uri, "range": tokenRange(span.Start, span.End)}
+}
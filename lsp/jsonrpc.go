@@ -0,0 +1,110 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"json-parser/parser"
+	"net/textproto"
+	"strconv"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// message is a decoded JSON-RPC request, notification or response. Requests
+// and responses carry an id; notifications do not.
+type message struct {
+	ID     any
+	HasID  bool
+	Method string
+	Params map[string]any
+	Result any
+	Error  map[string]any
+}
+
+// readMessage reads one base-protocol frame: headers, a blank line and a
+// body of Content-Length bytes.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(headers) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading message headers: %w", err)
+	}
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length header %q", headers.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("reading message body: %w", err)
+	}
+	return body, nil
+}
+
+// writeMessage frames v with a Content-Length header and writes it.
+func writeMessage(w io.Writer, v map[string]any) error {
+	v["jsonrpc"] = "2.0"
+	body, err := parser.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n", len(body))
+	buf.Write(body)
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// decodeMessage parses a message body with the project's own parser.
+func decodeMessage(body []byte) (*message, error) {
+	v, err := parser.ParseDialect(bytes.NewReader(body), parser.JSON)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("message must be an object")
+	}
+	m := &message{}
+	m.ID, m.HasID = obj["id"]
+	m.Method, _ = obj["method"].(string)
+	m.Params, _ = obj["params"].(map[string]any)
+	m.Result = obj["result"]
+	m.Error, _ = obj["error"].(map[string]any)
+	return m, nil
+}
+
+// rpcError is returned by handlers to send an error response.
+type rpcError struct {
+	Code    int
+	Message string
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// The helpers below dig typed fields out of decoded params; missing or
+// mistyped fields yield zero values.
+
+func object(v map[string]any, key string) map[string]any {
+	obj, _ := v[key].(map[string]any)
+	return obj
+}
+
+func stringField(v map[string]any, key string) string {
+	s, _ := v[key].(string)
+	return s
+}
+
+func intField(v map[string]any, key string) int {
+	f, _ := v[key].(float64)
+	return int(f)
+}
@@ -0,0 +1,175 @@
+// Package lsp implements a Language Server Protocol server for JSON, JSONC
+// and JSON5 documents on top of the parser package. It offers diagnostics
+// (syntax errors and, when a document names a local "$schema", schema
+// violations), a document outline, hover with the JSON Pointer under the
+// cursor, formatting, folding ranges and go-to-definition for "$ref".
+package lsp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Server speaks LSP over a pair of streams. Documents are synchronized in
+// full on every change.
+type Server struct {
+	docs     map[string]*document
+	out      io.Writer
+	shutdown bool
+}
+
+// NewServer returns a server with no open documents.
+func NewServer() *Server {
+	return &Server{docs: make(map[string]*document)}
+}
+
+// ErrExitWithoutShutdown is returned by Serve when the client sends exit
+// without asking for a shutdown first.
+var ErrExitWithoutShutdown = errors.New("exit notification received before shutdown")
+
+// Serve reads messages from r and writes responses and notifications to w
+// until the client sends exit or r is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	in := bufio.NewReader(r)
+	for {
+		body, err := readMessage(in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		msg, err := decodeMessage(body)
+		if err != nil {
+			if err := s.reply(nil, nil, &rpcError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches one message. Only errors writing to the client are
+// returned; handler failures become error responses.
+func (s *Server) handle(msg *message) error {
+	if msg.Method == "" {
+		// a response to a request we never send
+		return nil
+	}
+	if !msg.HasID {
+		return s.notification(msg.Method, msg.Params)
+	}
+	result, err := s.request(msg.Method, msg.Params)
+	var rerr *rpcError
+	if err != nil && !errors.As(err, &rerr) {
+		rerr = &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return s.reply(msg.ID, result, rerr)
+}
+
+func (s *Server) request(method string, params map[string]any) (any, error) {
+	if s.shutdown {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+	switch method {
+	case "initialize":
+		return map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           1, // full
+				"documentSymbolProvider":     true,
+				"hoverProvider":              true,
+				"documentFormattingProvider": true,
+				"foldingRangeProvider":       true,
+				"definitionProvider":         true,
+			},
+			"serverInfo": map[string]any{"name": "jsonls"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	}
+	handler := s.documentHandler(method)
+	if handler == nil {
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+	doc, ok := s.docs[stringField(object(params, "textDocument"), "uri")]
+	if !ok {
+		return nil, fmt.Errorf("document is not open")
+	}
+	return handler(doc, params), nil
+}
+
+// documentHandler returns the handler of a request about an open document,
+// or nil for unknown methods.
+func (s *Server) documentHandler(method string) func(*document, map[string]any) any {
+	switch method {
+	case "textDocument/documentSymbol":
+		return s.symbols
+	case "textDocument/hover":
+		return s.hover
+	case "textDocument/formatting":
+		return s.format
+	case "textDocument/foldingRange":
+		return s.foldingRanges
+	case "textDocument/definition":
+		return s.definition
+	}
+	return nil
+}
+
+// notification handles a client notification, returning the error of
+// writing any notification it sends back.
+func (s *Server) notification(method string, params map[string]any) error {
+	td := object(params, "textDocument")
+	uri := stringField(td, "uri")
+	switch method {
+	case "textDocument/didOpen":
+		doc := newDocument(uri, stringField(td, "languageId"), stringField(td, "text"))
+		s.docs[uri] = doc
+		return s.publishDiagnostics(doc)
+	case "textDocument/didChange":
+		doc, ok := s.docs[uri]
+		changes, _ := params["contentChanges"].([]any)
+		if !ok || len(changes) == 0 {
+			return nil
+		}
+		last, _ := changes[len(changes)-1].(map[string]any)
+		doc.setText(stringField(last, "text"))
+		return s.publishDiagnostics(doc)
+	case "textDocument/didClose":
+		delete(s.docs, uri)
+		return s.notify("textDocument/publishDiagnostics", map[string]any{"uri": uri, "diagnostics": []any{}})
+	}
+	return nil
+}
+
+func (s *Server) publishDiagnostics(doc *document) error {
+	return s.notify("textDocument/publishDiagnostics", map[string]any{"uri": doc.uri, "diagnostics": s.diagnostics(doc)})
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params map[string]any) error {
+	return writeMessage(s.out, map[string]any{"method": method, "params": params})
+}
+
+func (s *Server) reply(id, result any, err *rpcError) error {
+	msg := map[string]any{"id": id}
+	if err != nil {
+		msg["error"] = map[string]any{"code": err.Code, "message": err.Message}
+	} else {
+		msg["result"] = result
+	}
+	return writeMessage(s.out, msg)
+}
@@ -0,0 +1,395 @@
+package lsp
+
+import (
+	"bufio"
+	"io"
+	"json-parser/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// client drives a Server in-process the way an editor would.
+type client struct {
+	t        *testing.T
+	w        io.WriteCloser
+	messages chan *message
+	done     chan error
+	nextID   int
+}
+
+func startServer(t *testing.T) *client {
+	t.Helper()
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+	c := &client{t: t, w: clientW, messages: make(chan *message, 100), done: make(chan error, 1)}
+	go func() {
+		err := NewServer().Serve(serverR, serverW)
+		serverW.Close()
+		c.done <- err
+	}()
+	// read concurrently so the server never blocks writing a notification
+	go func() {
+		r := bufio.NewReader(clientR)
+		for {
+			body, err := readMessage(r)
+			if err != nil {
+				close(c.messages)
+				return
+			}
+			msg, err := decodeMessage(body)
+			if err != nil {
+				t.Errorf("server sent invalid JSON %s: %v", body, err)
+				continue
+			}
+			c.messages <- msg
+		}
+	}()
+	c.request("initialize", map[string]any{"capabilities": map[string]any{}})
+	c.notify("initialized", map[string]any{})
+	return c
+}
+
+func (c *client) send(msg map[string]any) {
+	c.t.Helper()
+	if err := writeMessage(c.w, msg); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *client) notify(method string, params map[string]any) {
+	c.t.Helper()
+	c.send(map[string]any{"method": method, "params": params})
+}
+
+// next returns the next message from the server.
+func (c *client) next() *message {
+	c.t.Helper()
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatalf("server closed the connection")
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("timed out waiting for the server")
+	}
+	return nil
+}
+
+// call sends a request and returns the response, failing on notifications
+// that arrive in between.
+func (c *client) call(method string, params map[string]any) *message {
+	c.t.Helper()
+	c.nextID++
+	c.send(map[string]any{"id": c.nextID, "method": method, "params": params})
+	msg := c.next()
+	if msg.Method != "" || msg.ID != float64(c.nextID) {
+		c.t.Fatalf("expected the response to request %d, got %+v", c.nextID, msg)
+	}
+	return msg
+}
+
+func (c *client) request(method string, params map[string]any) any {
+	c.t.Helper()
+	msg := c.call(method, params)
+	if msg.Error != nil {
+		c.t.Fatalf("%s failed: %v", method, msg.Error)
+	}
+	return msg.Result
+}
+
+// open opens a document and returns the diagnostics published for it.
+func (c *client) open(uri, languageID, text string) []any {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", map[string]any{"textDocument": map[string]any{
+		"uri": uri, "languageId": languageID, "version": 1, "text": text,
+	}})
+	return c.diagnostics(uri)
+}
+
+func (c *client) diagnostics(uri string) []any {
+	c.t.Helper()
+	msg := c.next()
+	if msg.Method != "textDocument/publishDiagnostics" || msg.Params["uri"] != uri {
+		c.t.Fatalf("expected diagnostics for %s, got %+v", uri, msg)
+	}
+	diags, _ := msg.Params["diagnostics"].([]any)
+	return diags
+}
+
+func (c *client) shutdown() {
+	c.t.Helper()
+	c.request("shutdown", nil)
+	c.notify("exit", nil)
+	select {
+	case err := <-c.done:
+		if err != nil {
+			c.t.Fatalf("server exited with %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		c.t.Fatalf("server did not exit")
+	}
+}
+
+func docParams(uri string, extra map[string]any) map[string]any {
+	params := map[string]any{"textDocument": map[string]any{"uri": uri}}
+	for k, v := range extra {
+		params[k] = v
+	}
+	return params
+}
+
+func pos(line, char int) map[string]any {
+	return map[string]any{"line": float64(line), "character": float64(char)}
+}
+
+func rng(l1, c1, l2, c2 int) map[string]any {
+	return map[string]any{"start": pos(l1, c1), "end": pos(l2, c2)}
+}
+
+func TestInitialize(t *testing.T) {
+	c := startServer(t)
+	c.nextID++
+	c.send(map[string]any{"id": "str-id", "method": "initialize", "params": map[string]any{}})
+	msg := c.next()
+	if msg.ID != "str-id" {
+		t.Fatalf("string ids must be echoed, got %v", msg.ID)
+	}
+	caps, _ := msg.Result.(map[string]any)["capabilities"].(map[string]any)
+	for _, name := range []string{"hoverProvider", "documentSymbolProvider", "documentFormattingProvider", "foldingRangeProvider", "definitionProvider"} {
+		if caps[name] != true {
+			t.Errorf("capability %s not advertised", name)
+		}
+	}
+	if msg := c.call("workspace/unknown", nil); msg.Error == nil || msg.Error["code"] != float64(codeMethodNotFound) {
+		t.Errorf("expected method not found, got %+v", msg)
+	}
+	c.shutdown()
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := startServer(t)
+	diags := c.open("file:///tmp/a.json", "json", "{\n  \"a\": 1\n  \"b\": tru\n}")
+	expected := []any{
+		map[string]any{"range": rng(1, 8, 2, 2), "severity": float64(severityError), "source": "jsonls", "message": "missing comma"},
+		map[string]any{"range": rng(2, 7, 2, 10), "severity": float64(severityError), "source": "jsonls", "message": `unquoted string "tru"`},
+	}
+	if !parser.Equal(any(diags), any(expected)) {
+		t.Fatalf("diagnostics mismatch:\nexpected %v\ngot      %v", expected, diags)
+	}
+
+	// fixing the document clears them
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": "file:///tmp/a.json", "version": 2},
+		"contentChanges": []any{map[string]any{"text": `{"a": 1, "b": true}`}},
+	})
+	if diags := c.diagnostics("file:///tmp/a.json"); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diags)
+	}
+
+	// comments are fine in JSONC
+	if diags := c.open("file:///tmp/b.jsonc", "", "// c\n{\"a\": 1,}"); len(diags) != 0 {
+		t.Fatalf("expected no diagnostics for jsonc, got %v", diags)
+	}
+	c.notify("textDocument/didClose", docParams("file:///tmp/b.jsonc", nil))
+	if diags := c.diagnostics("file:///tmp/b.jsonc"); len(diags) != 0 {
+		t.Fatalf("closing must clear diagnostics, got %v", diags)
+	}
+	c.shutdown()
+}
+
+func TestSchemaDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := `{
+  "type": "object",
+  "properties": {"port": {"type": "integer", "maximum": 65535}},
+  "required": ["name"]
+}`
+	if err := os.WriteFile(filepath.Join(dir, "config.schema.json"), []byte(schemaFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	uri := pathURI(filepath.Join(dir, "config.json"))
+	c := startServer(t)
+	diags := c.open(uri, "json", "{\n  \"$schema\": \"./config.schema.json\",\n  \"port\": 70000\n}")
+	if len(diags) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diags)
+	}
+	var messages []string
+	for _, d := range diags {
+		messages = append(messages, d.(map[string]any)["message"].(string))
+	}
+	joined := strings.Join(messages, "\n")
+	if !strings.Contains(joined, "(schema /properties/port/maximum)") {
+		t.Errorf("missing maximum violation in %q", joined)
+	}
+	if !strings.Contains(joined, "/required") {
+		t.Errorf("missing required violation in %q", joined)
+	}
+	for _, d := range diags {
+		d := d.(map[string]any)
+		if strings.Contains(d["message"].(string), "maximum") && !parser.Equal(d["range"], any(rng(2, 10, 2, 15))) {
+			t.Errorf("maximum violation should point at the value, got %v", d["range"])
+		}
+	}
+
+	// a missing schema file is a warning on the $schema value
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri},
+		"contentChanges": []any{map[string]any{"text": `{"$schema": "missing.json"}`}},
+	})
+	diags = c.diagnostics(uri)
+	if len(diags) != 1 || diags[0].(map[string]any)["severity"] != float64(severityWarning) {
+		t.Fatalf("expected a warning, got %v", diags)
+	}
+	c.shutdown()
+}
+
+const outlineDoc = `{
+  "name": "jsonls",
+  "tags": ["a", "😀"],
+  "owner": {
+    "id": 7,
+    "active": true
+  }
+}`
+
+func TestDocumentSymbols(t *testing.T) {
+	c := startServer(t)
+	c.open("file:///tmp/o.json", "json", outlineDoc)
+	got := c.request("textDocument/documentSymbol", docParams("file:///tmp/o.json", nil))
+	expected := []any{
+		map[string]any{"name": "name", "kind": float64(symbolString), "detail": `"jsonls"`,
+			"range": rng(1, 2, 1, 18), "selectionRange": rng(1, 2, 1, 8)},
+		map[string]any{"name": "tags", "kind": float64(symbolArray),
+			"range": rng(2, 2, 2, 21), "selectionRange": rng(2, 2, 2, 8),
+			"children": []any{
+				map[string]any{"name": "0", "kind": float64(symbolString), "detail": `"a"`, "range": rng(2, 11, 2, 14), "selectionRange": rng(2, 11, 2, 14)},
+				map[string]any{"name": "1", "kind": float64(symbolString), "detail": `"😀"`, "range": rng(2, 16, 2, 20), "selectionRange": rng(2, 16, 2, 20)},
+			}},
+		map[string]any{"name": "owner", "kind": float64(symbolObject),
+			"range": rng(3, 2, 6, 3), "selectionRange": rng(3, 2, 3, 9),
+			"children": []any{
+				map[string]any{"name": "id", "kind": float64(symbolNumber), "detail": "7", "range": rng(4, 4, 4, 11), "selectionRange": rng(4, 4, 4, 8)},
+				map[string]any{"name": "active", "kind": float64(symbolBoolean), "detail": "true", "range": rng(5, 4, 5, 18), "selectionRange": rng(5, 4, 5, 12)},
+			}},
+	}
+	if !parser.Equal(got, any(expected)) {
+		t.Fatalf("symbols mismatch:\nexpected %v\ngot      %v", expected, got)
+	}
+	c.shutdown()
+}
+
+func TestHover(t *testing.T) {
+	c := startServer(t)
+	c.open("file:///tmp/o.json", "json", outlineDoc)
+	tests := []struct {
+		at       map[string]any
+		expected string
+	}{
+		{pos(1, 4), "`/name` (string)"},
+		{pos(1, 12), "`/name` (string)"},
+		{pos(2, 17), "`/tags/1` (string)"},
+		{pos(2, 20), "`/tags` (array)"},
+		{pos(5, 15), "`/owner/active` (boolean)"},
+		{pos(0, 0), "`/` (object)"},
+	}
+	for _, tc := range tests {
+		got, _ := c.request("textDocument/hover", docParams("file:///tmp/o.json", map[string]any{"position": tc.at})).(map[string]any)
+		contents, _ := got["contents"].(map[string]any)
+		if contents["value"] != tc.expected {
+			t.Errorf("hover at %v: expected %s, got %v", tc.at, tc.expected, got)
+		}
+	}
+	c.shutdown()
+}
+
+func TestFormatting(t *testing.T) {
+	c := startServer(t)
+	text := "{\"b\":1, // one\n\"a\":[true,null]}"
+	c.open("file:///tmp/f.jsonc", "jsonc", text)
+	got := c.request("textDocument/formatting", docParams("file:///tmp/f.jsonc", map[string]any{
+		"options": map[string]any{"tabSize": 2, "insertSpaces": true},
+	}))
+	expected := []any{map[string]any{
+		"range":   rng(0, 0, 1, 16),
+		"newText": "{\n  \"b\": 1, // one\n  \"a\": [\n    true,\n    null\n  ]\n}\n",
+	}}
+	if !parser.Equal(got, any(expected)) {
+		t.Fatalf("formatting mismatch:\nexpected %v\ngot      %v", expected, got)
+	}
+	c.shutdown()
+}
+
+func TestFoldingRanges(t *testing.T) {
+	c := startServer(t)
+	c.open("file:///tmp/o.json", "json", outlineDoc)
+	got := c.request("textDocument/foldingRange", docParams("file:///tmp/o.json", nil))
+	expected := []any{
+		map[string]any{"startLine": 0.0, "endLine": 6.0},
+		map[string]any{"startLine": 3.0, "endLine": 5.0},
+	}
+	if !parser.Equal(got, any(expected)) {
+		t.Fatalf("folding mismatch:\nexpected %v\ngot      %v", expected, got)
+	}
+	c.shutdown()
+}
+
+func TestDefinition(t *testing.T) {
+	dir := t.TempDir()
+	common := "{\n  \"$defs\": {\n    \"id\": {\"type\": \"integer\"}\n  }\n}"
+	if err := os.WriteFile(filepath.Join(dir, "common.json"), []byte(common), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	uri := pathURI(filepath.Join(dir, "main.json"))
+	text := `{
+  "$defs": {"name": {"type": "string"}},
+  "properties": {
+    "name": {"$ref": "#/$defs/name"},
+    "id": {"$ref": "common.json#/$defs/id"}
+  }
+}`
+	c := startServer(t)
+	c.open(uri, "json", text)
+	local := c.request("textDocument/definition", docParams(uri, map[string]any{"position": pos(3, 23)}))
+	if expected := map[string]any{"uri": uri, "range": rng(1, 20, 1, 38)}; !parser.Equal(local, any(expected)) {
+		t.Errorf("local ref: expected %v, got %v", expected, local)
+	}
+	remote := c.request("textDocument/definition", docParams(uri, map[string]any{"position": pos(4, 20)}))
+	expected := map[string]any{"uri": pathURI(filepath.Join(dir, "common.json")), "range": rng(2, 10, 2, 29)}
+	if !parser.Equal(remote, any(expected)) {
+		t.Errorf("file ref: expected %v, got %v", expected, remote)
+	}
+	if got := c.request("textDocument/definition", docParams(uri, map[string]any{"position": pos(3, 6)})); got != nil {
+		t.Errorf("expected no definition on a key, got %v", got)
+	}
+	c.shutdown()
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	c := startServer(t)
+	c.notify("exit", nil)
+	if err := <-c.done; err != ErrExitWithoutShutdown {
+		t.Fatalf("expected ErrExitWithoutShutdown, got %v", err)
+	}
+}
+
+// failWriter fails every write.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestNotificationWriteError(t *testing.T) {
+	var in strings.Builder
+	open := map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+		"textDocument": map[string]any{"uri": "file:///a.json", "languageId": "json", "text": "{"},
+	}}
+	if err := writeMessage(&in, open); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewServer().Serve(strings.NewReader(in.String()), failWriter{}); err != io.ErrClosedPipe {
+		t.Fatalf("expected the diagnostics write error, got %v", err)
+	}
+}
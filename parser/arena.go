@@ -0,0 +1,350 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ValueKind is the type of an arena Value.
+type ValueKind uint8
+
+const (
+	KindNull ValueKind = iota
+	KindBool
+	KindNumber
+	KindString
+	KindArray
+	KindObject
+)
+
+func (k ValueKind) String() string {
+	switch k {
+	case KindBool:
+		return "boolean"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	}
+	return "null"
+}
+
+// Arena stores parsed documents in a few flat slices: one node per value,
+// children referenced by index, and the bytes of every string back to
+// back. Parsing many small documents into one arena, or into an arena that
+// is Reset between them, needs a handful of allocations instead of one per
+// object, array and string.
+//
+// An Arena is not safe for concurrent use.
+type Arena struct {
+	nodes []arenaNode
+	kids  []int32 // elements of arrays; keys and values, interleaved, of objects
+	bytes []byte  // contents of strings and keys
+	stack []int32 // children of the containers being parsed
+}
+
+type arenaNode struct {
+	kind ValueKind
+	num  float64 // the value of a number, 1 for true
+	// byte range in Arena.bytes for strings, index range in Arena.kids for
+	// arrays and objects
+	start, end int32
+}
+
+// errArenaFull is returned for documents whose strings or values do not fit
+// the 32-bit indexes of an arena.
+var errArenaFull = errors.New("document too large for an arena")
+
+// Value is a value stored in an Arena. It is only valid until the arena is
+// Reset. The zero Value is null.
+type Value struct {
+	a *Arena
+	i int32
+}
+
+// Reset empties the arena, keeping its memory for the next documents.
+// Values obtained before are invalidated.
+func (a *Arena) Reset() {
+	a.nodes = a.nodes[:0]
+	a.kids = a.kids[:0]
+	a.bytes = a.bytes[:0]
+	a.stack = a.stack[:0]
+}
+
+// Parse parses a single document written in dialect d into the arena. On
+// error the arena is left as it was.
+func (a *Arena) Parse(data []byte, d Dialect) (Value, error) {
+	nodes, kids, bytes := len(a.nodes), len(a.kids), len(a.bytes)
+	// a document has no more values, children or string bytes than bytes
+	if max(nodes, kids, bytes) > math.MaxInt32-len(data) {
+		return Value{}, errArenaFull
+	}
+	l := getBytesLexer(data, d)
+	// strings are copied into the arena, so the lexer need neither copy nor
+	// intern them
+	l.alias = true
+	in := l.interner
+	l.interner = nil
+	defer func() {
+		l.interner = in
+		putLexer(l)
+	}()
+	root, err := a.document(l)
+	if err != nil {
+		a.nodes, a.kids, a.bytes, a.stack = a.nodes[:nodes], a.kids[:kids], a.bytes[:bytes], a.stack[:0]
+		return Value{}, err
+	}
+	return Value{a: a, i: root}, nil
+}
+
+// ParseReader reads r to the end and parses it like Parse, reading into a
+// pooled buffer.
+func (a *Arena) ParseReader(r io.Reader, d Dialect) (Value, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(r); err != nil {
+		return Value{}, err
+	}
+	v, err := a.Parse(buf.Bytes(), d)
+	var terr *UnexpectedTokenError
+	if errors.As(err, &terr) {
+		// the token may alias the buffer, which goes back to the pool
+		terr.Got.Value = strings.Clone(terr.Got.Value)
+	}
+	return v, err
+}
+
+func (a *Arena) document(l *Lexer) (int32, error) {
+	tok, err := l.NextToken()
+	if err != nil {
+		return 0, err
+	}
+	root, err := a.value(l, tok)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := l.Expect(TokenEOF); err != nil {
+		return 0, err
+	}
+	return root, nil
+}
+
+func (a *Arena) value(l *Lexer, tok Token) (int32, error) {
+	switch tok.Type {
+	case TokenLeftBrace:
+		base := len(a.stack)
+		err := DecodeObject(l, tok, func(l *Lexer, key string, tok Token) error {
+			a.stack = append(a.stack, a.addString(key))
+			v, err := a.value(l, tok)
+			a.stack = append(a.stack, v)
+			return err
+		})
+		return a.addContainer(KindObject, base), err
+	case TokenLeftBracket:
+		base := len(a.stack)
+		err := DecodeArray(l, tok, func(l *Lexer, tok Token) error {
+			v, err := a.value(l, tok)
+			a.stack = append(a.stack, v)
+			return err
+		})
+		return a.addContainer(KindArray, base), err
+	case TokenNumber:
+		f, err := DecodeFloat(tok)
+		return a.add(arenaNode{kind: KindNumber, num: f}), err
+	case TokenString:
+		return a.addString(tok.Value), nil
+	case TokenTrue:
+		return a.add(arenaNode{kind: KindBool, num: 1}), nil
+	case TokenFalse:
+		return a.add(arenaNode{kind: KindBool}), nil
+	case TokenNull:
+		return a.add(arenaNode{kind: KindNull}), nil
+	default:
+		return 0, &UnexpectedTokenError{Got: tok, Want: "value"}
+	}
+}
+
+func (a *Arena) add(n arenaNode) int32 {
+	a.nodes = append(a.nodes, n)
+	return int32(len(a.nodes) - 1)
+}
+
+func (a *Arena) addString(s string) int32 {
+	start := len(a.bytes)
+	a.bytes = append(a.bytes, s...)
+	return a.add(arenaNode{kind: KindString, start: int32(start), end: int32(len(a.bytes))})
+}
+
+// addContainer moves the children pushed on the stack since base to kids.
+func (a *Arena) addContainer(kind ValueKind, base int) int32 {
+	start := len(a.kids)
+	a.kids = append(a.kids, a.stack[base:]...)
+	a.stack = a.stack[:base]
+	return a.add(arenaNode{kind: kind, start: int32(start), end: int32(len(a.kids))})
+}
+
+func (v Value) node() arenaNode {
+	if v.a == nil {
+		return arenaNode{}
+	}
+	return v.a.nodes[v.i]
+}
+
+// Kind returns the type of v.
+func (v Value) Kind() ValueKind { return v.node().kind }
+
+// Bool returns the value of a boolean, or false for other kinds.
+func (v Value) Bool() bool {
+	n := v.node()
+	return n.kind == KindBool && n.num != 0
+}
+
+// Float returns the value of a number, or 0 for other kinds.
+func (v Value) Float() float64 {
+	n := v.node()
+	if n.kind != KindNumber {
+		return 0
+	}
+	return n.num
+}
+
+// Bytes returns the contents of a string, or nil for other kinds. The
+// slice points into the arena: it must not be modified and is only valid
+// until Reset.
+func (v Value) Bytes() []byte {
+	n := v.node()
+	if n.kind != KindString {
+		return nil
+	}
+	return v.a.bytes[n.start:n.end:n.end]
+}
+
+// Str returns a copy of the contents of a string, or "" for other kinds.
+func (v Value) Str() string { return string(v.Bytes()) }
+
+// Len returns the number of elements of an array or members of an object,
+// or 0 for other kinds.
+func (v Value) Len() int {
+	n := v.node()
+	switch n.kind {
+	case KindArray:
+		return int(n.end - n.start)
+	case KindObject:
+		return int(n.end-n.start) / 2
+	}
+	return 0
+}
+
+// Index returns element i of an array. It panics if v is not an array or i
+// is out of range.
+func (v Value) Index(i int) Value {
+	n := v.node()
+	if n.kind != KindArray {
+		panic("parser: Index of " + n.kind.String())
+	}
+	if i < 0 || i >= v.Len() {
+		panic("parser: array index out of range")
+	}
+	return Value{a: v.a, i: v.a.kids[int(n.start)+i]}
+}
+
+// Member returns the key and value of member i of an object, in source
+// order. It panics if v is not an object or i is out of range.
+func (v Value) Member(i int) (key []byte, value Value) {
+	n := v.node()
+	if n.kind != KindObject {
+		panic("parser: Member of " + n.kind.String())
+	}
+	if i < 0 || i >= v.Len() {
+		panic("parser: member index out of range")
+	}
+	at := int(n.start) + 2*i
+	return Value{a: v.a, i: v.a.kids[at]}.Bytes(), Value{a: v.a, i: v.a.kids[at+1]}
+}
+
+// Get returns the value of the member of an object named key. When a key
+// appears more than once the last member wins, as in the maps built by the
+// other parsers.
+func (v Value) Get(key string) (Value, bool) {
+	for i := v.Len() - 1; i >= 0 && v.Kind() == KindObject; i-- {
+		if k, value := v.Member(i); string(k) == key {
+			return value, true
+		}
+	}
+	return Value{}, false
+}
+
+// Interface converts v to the representation ParseDialect returns: maps,
+// slices, strings, float64, bool and nil, sharing no memory with the arena.
+func (v Value) Interface() any {
+	n := v.node()
+	switch n.kind {
+	case KindBool:
+		return n.num != 0
+	case KindNumber:
+		return n.num
+	case KindString:
+		return v.Str()
+	case KindArray:
+		arr := make([]any, v.Len())
+		for i := range arr {
+			arr[i] = v.Index(i).Interface()
+		}
+		return arr
+	case KindObject:
+		obj := make(map[string]any, v.Len())
+		for i := range v.Len() {
+			k, value := v.Member(i)
+			obj[string(k)] = value.Interface()
+		}
+		return obj
+	}
+	return nil
+}
+
+// String formats v as compact JSON, with object members in source order.
+func (v Value) String() string {
+	return string(v.AppendJSON(nil))
+}
+
+// AppendJSON appends v to dst as compact JSON, with object members in
+// source order.
+func (v Value) AppendJSON(dst []byte) []byte {
+	n := v.node()
+	switch n.kind {
+	case KindBool:
+		return strconv.AppendBool(dst, n.num != 0)
+	case KindNumber:
+		return AppendFloat(dst, n.num)
+	case KindString:
+		return AppendString(dst, string(v.Bytes()))
+	case KindArray:
+		dst = append(dst, '[')
+		for i := range v.Len() {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			dst = v.Index(i).AppendJSON(dst)
+		}
+		return append(dst, ']')
+	case KindObject:
+		dst = append(dst, '{')
+		for i := range v.Len() {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			k, value := v.Member(i)
+			dst = append(AppendString(dst, string(k)), ':')
+			dst = value.AppendJSON(dst)
+		}
+		return append(dst, '}')
+	}
+	return append(dst, "null"...)
+}
@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArenaMatchesParseDialect(t *testing.T) {
+	files, _ := filepath.Glob("../test_data/*.json")
+	var a Arena
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ParseDialect(bytes.NewReader(data), JSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := a.Parse(data, JSON)
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if got := v.Interface(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: arena value differs from ParseDialect", file)
+		}
+	}
+}
+
+func TestArenaValue(t *testing.T) {
+	var a Arena
+	v, err := a.Parse([]byte(`{"b": [1, "two", true, null, {}], "a": "x\ny", "b": false}`), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Kind() != KindObject || v.Len() != 3 {
+		t.Fatalf("root: kind %v, len %d", v.Kind(), v.Len())
+	}
+	if k, first := v.Member(0); string(k) != "b" || first.Kind() != KindArray || first.Len() != 5 {
+		t.Fatalf("first member %q: %v", k, first)
+	}
+	_, first := v.Member(0)
+	if first.Index(0).Float() != 1 || first.Index(1).Str() != "two" || !first.Index(2).Bool() || first.Index(3).Kind() != KindNull || first.Index(4).Len() != 0 {
+		t.Fatalf("elements: %v", first)
+	}
+	if b, ok := v.Get("b"); !ok || b.Kind() != KindBool || b.Bool() {
+		t.Fatalf(`Get("b") = %v, %v; want the last member`, b, ok)
+	}
+	if s, ok := v.Get("a"); !ok || s.Str() != "x\ny" {
+		t.Fatalf(`Get("a") = %v, %v`, s, ok)
+	}
+	if _, ok := v.Get("c"); ok {
+		t.Fatalf(`Get("c") found a member`)
+	}
+	if got := v.String(); got != `{"b":[1,"two",true,null,{}],"a":"x\ny","b":false}` {
+		t.Fatalf("String() = %s", got)
+	}
+	if (Value{}).Kind() != KindNull || (Value{}).String() != "null" {
+		t.Fatalf("zero Value is not null")
+	}
+}
+
+func TestArenaReuse(t *testing.T) {
+	var a Arena
+	first, err := a.Parse([]byte(`{"id": 1, "tags": ["a", "b"]}`), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a failed parse leaves earlier values alone
+	if _, err := a.Parse([]byte(`{"id": 2, "tags": ["c",`), JSON); err == nil {
+		t.Fatalf("truncated document parsed")
+	}
+	second, err := a.ParseReader(bytes.NewReader([]byte(`[{"id": 2}]`)), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.String() != `{"id":1,"tags":["a","b"]}` || second.String() != `[{"id":2}]` {
+		t.Fatalf("got %s and %s", first, second)
+	}
+	nodes, bytesCap := cap(a.nodes), cap(a.bytes)
+	a.Reset()
+	v, err := a.Parse([]byte(`{"id": 3, "tags": []}`), JSON)
+	if err != nil || v.String() != `{"id":3,"tags":[]}` {
+		t.Fatalf("after Reset: %v, %v", v, err)
+	}
+	if cap(a.nodes) != nodes || cap(a.bytes) != bytesCap {
+		t.Fatalf("Reset did not keep the arena's memory")
+	}
+	if v, err := a.Parse([]byte(`{a: 'b',}`), JSON5); err != nil || v.String() != `{"a":"b"}` {
+		t.Fatalf("JSON5: %v, %v", v, err)
+	}
+}
+
+func TestArenaParseReaderError(t *testing.T) {
+	var a Arena
+	_, err := a.ParseReader(strings.NewReader(`["abc" "def"]`), JSON)
+	var terr *UnexpectedTokenError
+	if !errors.As(err, &terr) || terr.Got.Value != "def" {
+		t.Fatalf("want an unexpected \"def\", got %v", err)
+	}
+	// the next document reuses the pooled buffer
+	if _, err := a.ParseReader(strings.NewReader(`["xyz","uvw"]`), JSON); err != nil {
+		t.Fatal(err)
+	}
+	if terr.Got.Value != "def" {
+		t.Fatalf("error token changed to %q", terr.Got.Value)
+	}
+}
+
+func TestArenaAllocations(t *testing.T) {
+	data, err := os.ReadFile("../test_data/example_todos.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a Arena
+	if _, err := a.Parse(data, JSON); err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		a.Reset()
+		if _, err := a.Parse(data, JSON); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// a warm arena only allocates for strings with escape sequences
+	if allocs > 5 {
+		t.Fatalf("%v allocations per parse into a reused arena", allocs)
+	}
+}
+
+func BenchmarkArena(b *testing.B) {
+	data, err := os.ReadFile("../test_data/example_todos.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("ParseDialect", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseDialect(bytes.NewReader(data), JSON); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Arena", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		var a Arena
+		for i := 0; i < b.N; i++ {
+			a.Reset()
+			if _, err := a.Parse(data, JSON); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ArenaPerRecord", func(b *testing.B) {
+		// many small documents, as when ingesting one record at a time
+		records := bytes.Split(bytes.Trim(bytes.TrimSpace(data), "[]"), []byte("},"))
+		for i := range records[:len(records)-1] {
+			records[i] = append(records[i], '}')
+		}
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		var a Arena
+		for i := 0; i < b.N; i++ {
+			for _, rec := range records {
+				a.Reset()
+				if _, err := a.Parse(rec, JSON); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// suiteDir holds the parsing cases of JSONTestSuite; see its README.
+const suiteDir = "testdata/JSONTestSuite/test_parsing"
+
+// knownFailures lists the cases some of the strict parsers get wrong, with
+// the reason. TestJSONTestSuite fails once every parser handles one of them,
+// so that the list only shrinks.
+var knownFailures = map[string]string{}
+
+// suiteParsers are the strict entry points run over the suite; each
+// reports whether it accepted the input. BasicParase is lenient by design
+// and not among them.
+var suiteParsers = []struct {
+	name  string
+	parse func(path string, data []byte) error
+}{
+	{"ParseDialect", func(_ string, data []byte) error {
+		_, err := ParseDialect(bytes.NewReader(data), JSON)
+		return err
+	}},
+	{"NewLexerBytes", func(_ string, data []byte) error {
+		_, err := parseDocument(NewLexerBytes(data, JSON))
+		return err
+	}},
+	{"Arena", func(_ string, data []byte) error {
+		var a Arena
+		_, err := a.Parse(data, JSON)
+		return err
+	}},
+	{"ParseFile", func(path string, _ []byte) error {
+		_, err := ParseFile(path)
+		return err
+	}},
+	{"ParseRecover", func(_ string, data []byte) error {
+		_, diags, err := ParseRecover(bytes.NewReader(data), JSON)
+		if err != nil {
+			return err
+		}
+		for _, d := range diags {
+			if d.Severity == SeverityError {
+				return fmt.Errorf("%v", d)
+			}
+		}
+		return nil
+	}},
+}
+
+// runCase parses one case, turning a panic into an error.
+func runCase(parse func(string, []byte) error, path string, data []byte) (err error, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, panicked = fmt.Errorf("panic: %v", r), true
+		}
+	}()
+	return parse(path, data), false
+}
+
+func TestJSONTestSuite(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(suiteDir, "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no JSONTestSuite cases in %s: %v", suiteDir, err)
+	}
+	failing := map[string]bool{}
+	ran := 0
+	for _, p := range suiteParsers {
+		t.Run(p.name, func(t *testing.T) {
+			ran++
+			var passed, total int
+			var implementation []string
+			for _, path := range files {
+				name := filepath.Base(path)
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err, panicked := runCase(p.parse, path, data)
+				if panicked {
+					t.Errorf("%s: %v", name, err)
+					continue
+				}
+				accepted := err == nil
+				var ok bool
+				switch name[0] {
+				case 'y':
+					ok = accepted
+				case 'n':
+					ok = !accepted
+				default:
+					implementation = append(implementation, fmt.Sprintf("%s: accepted %v", name, accepted))
+					continue
+				}
+				total++
+				reason, known := knownFailures[name]
+				if !ok {
+					failing[name] = true
+				}
+				switch {
+				case !ok && !known && accepted:
+					t.Errorf("%s: accepted, want an error", name)
+				case !ok && !known:
+					t.Errorf("%s: rejected: %v", name, err)
+				case !ok:
+					t.Logf("%s: known failure: %s", name, reason)
+				}
+				if ok {
+					passed++
+				}
+			}
+			t.Logf("%d of %d y_ and n_ cases conform", passed, total)
+			sort.Strings(implementation)
+			t.Logf("implementation-defined cases:\n\t%s", strings.Join(implementation, "\n\t"))
+		})
+	}
+	if ran < len(suiteParsers) {
+		// some parsers were filtered out with -run
+		return
+	}
+	for name := range knownFailures {
+		if !failing[name] {
+			t.Errorf("%s now conforms; remove it from knownFailures", name)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// contextCheckInterval is the number of tokens read between two looks at
+// the context of a Lexer.
+const contextCheckInterval = 256
+
+// CanceledError is returned when parsing stops because its context is done.
+// It records how far the input had been read.
+type CanceledError struct {
+	Err    error    // context.Canceled or context.DeadlineExceeded
+	At     Position // position of the next unread byte
+	Tokens int      // number of tokens read
+}
+
+func (e *CanceledError) Error() string {
+	return fmt.Sprintf("parsing stopped at line %d, column %d (offset %d) after %d tokens: %v", e.At.Line, e.At.Column, e.At.Offset, e.Tokens, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error { return e.Err }
+
+// WithContext makes NextToken fail with a *CanceledError once ctx is done,
+// and with it DecodeAny, DecodeArray, DecodeObject, SkipValue and the
+// generated decoders driving l. The context is checked before the first
+// token and then every few hundred tokens, so a single very long token is
+// read to its end. WithContext returns l.
+func (l *Lexer) WithContext(ctx context.Context) *Lexer {
+	l.ctx = ctx
+	l.tokens = 0
+	return l
+}
+
+// checkContext is called before every token of a Lexer with a context.
+func (l *Lexer) checkContext() error {
+	n := l.tokens
+	l.tokens++
+	if n%contextCheckInterval != 0 {
+		return nil
+	}
+	select {
+	case <-l.ctx.Done():
+		return &CanceledError{Err: l.ctx.Err(), At: l.position(), Tokens: n}
+	default:
+		return nil
+	}
+}
+
+// ParseContext parses a single JSON document like ParseDialect, giving up
+// with a *CanceledError when ctx is done.
+func ParseContext(ctx context.Context, r io.Reader) (any, error) {
+	return ParseDialectContext(ctx, r, JSON)
+}
+
+// ParseDialectContext is ParseContext for a document written in dialect d.
+func ParseDialectContext(ctx context.Context, r io.Reader, d Dialect) (any, error) {
+	l := getLexer(r, d).WithContext(ctx)
+	defer putLexer(l)
+	return parseDocument(l)
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseContext(t *testing.T) {
+	data, err := os.ReadFile("../test_data/example_posts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ParseDialect(bytes.NewReader(data), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseContext(context.Background(), bytes.NewReader(data))
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseContext differs from ParseDialect, error %v", err)
+	}
+	if _, err := ParseContext(context.Background(), strings.NewReader(`[1, 2`)); err == nil {
+		t.Fatalf("syntax error not reported")
+	}
+}
+
+func TestParseContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := ParseContext(ctx, strings.NewReader(`{"a": 1}`))
+	var cerr *CanceledError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &cerr) {
+		t.Fatalf("got error %v, want a *CanceledError wrapping context.Canceled", err)
+	}
+	if cerr.Tokens != 0 || cerr.At.Offset != 0 || cerr.At.Line != 1 {
+		t.Fatalf("progress %+v, want nothing read", cerr)
+	}
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := ParseContext(ctx, strings.NewReader(`[]`)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestDecodeArrayCanceledMidway(t *testing.T) {
+	doc := "[" + strings.Repeat("1,\n", 10000) + "1]"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l := NewLexer(strings.NewReader(doc)).WithContext(ctx)
+	tok, err := l.NextToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	err = DecodeArray(l, tok, func(l *Lexer, tok Token) error {
+		if n++; n == 1000 {
+			cancel()
+		}
+		return SkipValue(l, tok)
+	})
+	var cerr *CanceledError
+	if !errors.As(err, &cerr) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want a *CanceledError", err)
+	}
+	// every element is two tokens; the context is looked at every
+	// contextCheckInterval of them
+	if n < 1000 || n > 1000+contextCheckInterval/2+1 {
+		t.Fatalf("decoded %d elements after canceling at 1000", n)
+	}
+	if cerr.At.Line < 1000 || cerr.At.Line > n+1 || cerr.At.Offset < 3*1000 || cerr.Tokens < 2*1000 {
+		t.Fatalf("stopped at %+v after %d elements", cerr.At, n)
+	}
+	if !strings.Contains(err.Error(), "context canceled") || !strings.Contains(err.Error(), "parsing stopped at line") {
+		t.Fatalf("message %q", err)
+	}
+}
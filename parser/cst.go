@@ -0,0 +1,295 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSTToken is a Token together with its exact source spelling and the
+// trivia (whitespace and comments) around it. Leading holds the trivia
+// before the token that is not on the previous token's line, Trailing the
+// trivia after it up to the end of its line.
+type CSTToken struct {
+	Token
+	Raw      string
+	Leading  string
+	Trailing string
+}
+
+type NodeKind int
+
+const (
+	NodeScalar NodeKind = iota // string, number, true, false or null
+	NodeObject
+	NodeArray
+)
+
+// Node is one value in a concrete syntax tree. Scalars keep their token in
+// Open; objects and arrays keep their brackets in Open and Close.
+type Node struct {
+	Kind     NodeKind
+	Open     *CSTToken
+	Close    *CSTToken
+	Members  []*Member  // NodeObject
+	Elements []*Element // NodeArray
+}
+
+// Member is one key/value pair of an object. Comma is nil for the last
+// member unless the input had a trailing comma.
+type Member struct {
+	Key   *CSTToken
+	Colon *CSTToken
+	Value *Node
+	Comma *CSTToken
+}
+
+// Element is one value of an array. Comma follows the same rule as for
+// Member.
+type Element struct {
+	Value *Node
+	Comma *CSTToken
+}
+
+// CST is a lossless syntax tree: printing it reproduces the input byte for
+// byte, comments and formatting included.
+type CST struct {
+	Root *Node
+	// EOF carries the trivia after the last value.
+	EOF     *CSTToken
+	Dialect Dialect
+}
+
+// ParseCST reads a document in dialect d into a concrete syntax tree.
+func ParseCST(r io.Reader, d Dialect) (*CST, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	toks, err := cstTokens(src, d)
+	if err != nil {
+		return nil, err
+	}
+	p := cstParser{toks: toks, dialect: d}
+	root, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	eof := p.take()
+	if eof.Type != TokenEOF {
+		return nil, &UnexpectedTokenError{Got: eof.Token, Want: "end of input"}
+	}
+	return &CST{Root: root, EOF: eof, Dialect: d}, nil
+}
+
+// cstTokens lexes src and attaches raw text and trivia to every token.
+func cstTokens(src []byte, d Dialect) ([]*CSTToken, error) {
+	l := NewLexerDialect(bytes.NewReader(src), d)
+	var toks []*CSTToken
+	prevEnd := 0
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			return nil, err
+		}
+		start, end := l.Span()
+		ct := &CSTToken{Token: tok, Raw: string(src[start:end])}
+		gap := string(src[prevEnd:start])
+		if len(toks) == 0 {
+			ct.Leading = gap
+		} else {
+			split := lineEnd(gap)
+			toks[len(toks)-1].Trailing = gap[:split]
+			ct.Leading = gap[split:]
+		}
+		toks = append(toks, ct)
+		if tok.Type == TokenEOF {
+			return toks, nil
+		}
+		prevEnd = end
+	}
+}
+
+// lineEnd returns the offset of the first newline in a run of trivia that
+// is not inside a block comment, or len(trivia) if there is none.
+func lineEnd(trivia string) int {
+	for i := 0; i < len(trivia); i++ {
+		switch {
+		case trivia[i] == '\n':
+			return i
+		case strings.HasPrefix(trivia[i:], "/*"):
+			if end := strings.Index(trivia[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			}
+		}
+	}
+	return len(trivia)
+}
+
+type cstParser struct {
+	toks    []*CSTToken
+	i       int
+	dialect Dialect
+	depth   int // containers being parsed, at most MaxDepth
+}
+
+func (p *cstParser) take() *CSTToken {
+	t := p.toks[p.i]
+	if p.i < len(p.toks)-1 {
+		p.i++
+	}
+	return t
+}
+
+func (p *cstParser) peek() *CSTToken {
+	return p.toks[p.i]
+}
+
+func (p *cstParser) value() (*Node, error) {
+	t := p.take()
+	switch t.Type {
+	case TokenLeftBrace, TokenLeftBracket:
+		if p.depth++; p.depth > MaxDepth {
+			return nil, &SyntaxError{Msg: fmt.Sprintf("nesting deeper than %d", MaxDepth), Offset: t.Start.Offset}
+		}
+		defer func() { p.depth-- }()
+		if t.Type == TokenLeftBrace {
+			return p.object(t)
+		}
+		return p.array(t)
+	case TokenNumber:
+		// numbers are kept as written but must still be representable
+		if _, err := DecodeFloat(t.Token); err != nil {
+			return nil, err
+		}
+		return &Node{Kind: NodeScalar, Open: t}, nil
+	case TokenString, TokenTrue, TokenFalse, TokenNull:
+		return &Node{Kind: NodeScalar, Open: t}, nil
+	default:
+		return nil, &UnexpectedTokenError{Got: t.Token, Want: "value"}
+	}
+}
+
+func (p *cstParser) object(open *CSTToken) (*Node, error) {
+	n := &Node{Kind: NodeObject, Open: open}
+	for {
+		t := p.take()
+		if t.Type == TokenRightBrace && (len(n.Members) == 0 || p.dialect != JSON) {
+			n.Close = t
+			return n, nil
+		}
+		if !isKey(p.dialect, t.Token) {
+			return nil, &UnexpectedTokenError{Got: t.Token, Want: "object key"}
+		}
+		m := &Member{Key: t, Colon: p.take()}
+		if m.Colon.Type != TokenColon {
+			return nil, &UnexpectedTokenError{Got: m.Colon.Token, Want: "':'"}
+		}
+		var err error
+		if m.Value, err = p.value(); err != nil {
+			return nil, err
+		}
+		n.Members = append(n.Members, m)
+		switch t := p.take(); t.Type {
+		case TokenComma:
+			m.Comma = t
+		case TokenRightBrace:
+			n.Close = t
+			return n, nil
+		default:
+			return nil, &UnexpectedTokenError{Got: t.Token, Want: "',' or '}'"}
+		}
+	}
+}
+
+func (p *cstParser) array(open *CSTToken) (*Node, error) {
+	n := &Node{Kind: NodeArray, Open: open}
+	for {
+		if t := p.peek(); t.Type == TokenRightBracket && (len(n.Elements) == 0 || p.dialect != JSON) {
+			n.Close = p.take()
+			return n, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		e := &Element{Value: v}
+		n.Elements = append(n.Elements, e)
+		switch t := p.take(); t.Type {
+		case TokenComma:
+			e.Comma = t
+		case TokenRightBracket:
+			n.Close = t
+			return n, nil
+		default:
+			return nil, &UnexpectedTokenError{Got: t.Token, Want: "',' or ']'"}
+		}
+	}
+}
+
+// Tokens returns every token of the tree in source order, ending with EOF.
+func (c *CST) Tokens() []*CSTToken {
+	var out []*CSTToken
+	c.Root.walkTokens(func(t *CSTToken) { out = append(out, t) })
+	return append(out, c.EOF)
+}
+
+func (n *Node) walkTokens(fn func(*CSTToken)) {
+	fn(n.Open)
+	for _, m := range n.Members {
+		fn(m.Key)
+		fn(m.Colon)
+		m.Value.walkTokens(fn)
+		if m.Comma != nil {
+			fn(m.Comma)
+		}
+	}
+	for _, e := range n.Elements {
+		e.Value.walkTokens(fn)
+		if e.Comma != nil {
+			fn(e.Comma)
+		}
+	}
+	if n.Close != nil {
+		fn(n.Close)
+	}
+}
+
+// WriteTo prints the tree. For an unmodified tree the output is identical
+// to the parsed input.
+func (c *CST) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	for _, t := range c.Tokens() {
+		buf.WriteString(t.Leading)
+		buf.WriteString(t.Raw)
+		buf.WriteString(t.Trailing)
+	}
+	return buf.WriteTo(w)
+}
+
+func (c *CST) String() string {
+	var b strings.Builder
+	_, _ = c.WriteTo(&b)
+	return b.String()
+}
+
+// Value converts the subtree into the parser's value representation.
+func (n *Node) Value() any {
+	switch n.Kind {
+	case NodeObject:
+		obj := make(map[string]any, len(n.Members))
+		for _, m := range n.Members {
+			obj[m.Key.Value] = m.Value.Value()
+		}
+		return obj
+	case NodeArray:
+		arr := make([]any, 0, len(n.Elements))
+		for _, e := range n.Elements {
+			arr = append(arr, e.Value.Value())
+		}
+		return arr
+	default:
+		return parseLiteral(n.Open.Token)
+	}
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCSTRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		dialect Dialect
+	}{
+		{"compact", `{"a":1,"b":[true,false,null]}`, JSON},
+		{"spelling kept", "{ \"n\" : 1.0, \"e\": 1E+2, \"s\": \"caf\\u00e9\\n\" }\n", JSON},
+		{"empty containers", " [ {}, [ ] ] ", JSON},
+		{"scalar root", "\n\t\"just a string\"\n", JSON},
+		{"comments", "// header\n{\n  \"a\": 1, // one\n  /* multi\n     line */\n  \"b\": [1, 2,], /* tail */\n}\n// footer\n", JSONC},
+		{"json5", "{unquoted: 'single', hex: 0xFF, trailing: [.5, +1,],}", JSON5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cst, err := ParseCST(strings.NewReader(tt.input), tt.dialect)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := cst.String(); got != tt.input {
+				t.Fatalf("round trip mismatch:\nwant %q\ngot  %q", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestCSTRoundTripTestData(t *testing.T) {
+	for _, name := range []string{"albums", "posts", "todos", "users"} {
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile("../test_data/example_" + name + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			cst, err := ParseCST(strings.NewReader(string(src)), JSON)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cst.String() != string(src) {
+				t.Fatalf("round trip changed the file")
+			}
+			if !Equal(cst.Root.Value(), runParser(string(src))) {
+				t.Fatalf("CST value disagrees with BasicParase")
+			}
+		})
+	}
+}
+
+func TestCSTTrivia(t *testing.T) {
+	input := "{\n  \"a\": 1, // one\n  /* about b */ \"b\": 2\n}"
+	cst, err := ParseCST(strings.NewReader(input), JSONC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a, b := cst.Root.Members[0], cst.Root.Members[1]
+	if a.Comma.Trailing != " // one" {
+		t.Errorf("comma trailing trivia: %q", a.Comma.Trailing)
+	}
+	if b.Key.Leading != "\n  /* about b */ " {
+		t.Errorf("key b leading trivia: %q", b.Key.Leading)
+	}
+	if a.Value.Open.Raw != "1" || a.Value.Open.Start.Offset != 9 || a.Value.Open.End.Offset != 10 {
+		t.Errorf("unexpected span for value of a: %+v", a.Value.Open)
+	}
+	if cst.Root.Close.Leading != "\n" {
+		t.Errorf("closing brace leading trivia: %q", cst.Root.Close.Leading)
+	}
+}
+
+func TestCSTRawSpelling(t *testing.T) {
+	cst, err := ParseCST(strings.NewReader(`["a\/b", 1.50, -0]`), JSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []struct{ raw, value string }{{`"a\/b"`, "a/b"}, {"1.50", "1.50"}, {"-0", "-0"}}
+	for i, e := range cst.Root.Elements {
+		if e.Value.Open.Raw != want[i].raw || e.Value.Open.Value != want[i].value {
+			t.Errorf("element %d: want raw %q value %q, got %q %q", i, want[i].raw, want[i].value, e.Value.Open.Raw, e.Value.Open.Value)
+		}
+	}
+}
+
+func TestCSTErrors(t *testing.T) {
+	for _, input := range []string{`{"a" 1}`, `[1 2]`, `{"a":1,}`, `[1] 2`, `{`} {
+		if _, err := ParseCST(strings.NewReader(input), JSON); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The edit methods below change a CST in place while touching only the
+// tokens of the affected value: indentation, comments and member order
+// everywhere else are kept exactly as they were.
+
+// Lookup returns the node a JSON Pointer refers to.
+func (c *CST) Lookup(pointer string) (*Node, error) {
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return c.lookup(p)
+}
+
+func (c *CST) lookup(p Pointer) (*Node, error) {
+	n := c.Root
+	for i, tok := range p {
+		switch n.Kind {
+		case NodeObject:
+			_, m := n.member(tok)
+			if m == nil {
+				return nil, fmt.Errorf("json pointer %q: member %q not found", p[:i+1].String(), tok)
+			}
+			n = m.Value
+		case NodeArray:
+			idx, err := arrayIndex(tok, len(n.Elements))
+			if err != nil {
+				return nil, fmt.Errorf("json pointer %q: %w", p[:i+1].String(), err)
+			}
+			n = n.Elements[idx].Value
+		default:
+			return nil, fmt.Errorf("json pointer %q: cannot index into a scalar", p[:i+1].String())
+		}
+	}
+	return n, nil
+}
+
+func (n *Node) member(key string) (int, *Member) {
+	for i, m := range n.Members {
+		if m.Key.Value == key {
+			return i, m
+		}
+	}
+	return -1, nil
+}
+
+// Set stores v at pointer. An existing value is replaced in place, keeping
+// the trivia around it; a missing object member is inserted as with
+// InsertMember, and "-" or the array length appends to an array.
+func (c *CST) Set(pointer string, v any) error {
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(p) == 0 {
+		node, err := c.render(v, "")
+		if err != nil {
+			return err
+		}
+		c.Root = replaceNode(c.Root, node)
+		return nil
+	}
+	parentPath, last := p.Parent()
+	parent, err := c.lookup(parentPath)
+	if err != nil {
+		return err
+	}
+	switch parent.Kind {
+	case NodeObject:
+		if _, m := parent.member(last); m != nil {
+			node, err := c.render(v, c.lineIndent(m.Key))
+			if err != nil {
+				return err
+			}
+			m.Value = replaceNode(m.Value, node)
+			return nil
+		}
+		return c.insertMember(parent, last, v)
+	case NodeArray:
+		if last == "-" || last == strconv.Itoa(len(parent.Elements)) {
+			return c.appendElement(parent, v)
+		}
+		idx, err := arrayIndex(last, len(parent.Elements))
+		if err != nil {
+			return fmt.Errorf("json pointer %q: %w", pointer, err)
+		}
+		e := parent.Elements[idx]
+		node, err := c.render(v, c.lineIndent(e.Value.Open))
+		if err != nil {
+			return err
+		}
+		e.Value = replaceNode(e.Value, node)
+		return nil
+	default:
+		return fmt.Errorf("json pointer %q: cannot index into a scalar", pointer)
+	}
+}
+
+// InsertMember adds key with value v as the last member of the object at
+// pointer, laid out like the members already there.
+func (c *CST) InsertMember(pointer, key string, v any) error {
+	n, err := c.Lookup(pointer)
+	if err != nil {
+		return err
+	}
+	if n.Kind != NodeObject {
+		return fmt.Errorf("json pointer %q does not refer to an object", pointer)
+	}
+	if _, m := n.member(key); m != nil {
+		return fmt.Errorf("member %q already exists", key)
+	}
+	return c.insertMember(n, key, v)
+}
+
+func (c *CST) insertMember(n *Node, key string, v any) error {
+	leading, sep := c.itemLayout(n)
+	if count := len(n.Members); count > 0 {
+		prev := n.Members[count-1]
+		leading = c.breakAfterComment(leading, prev.Key, prev.Comma, prev.Value)
+	}
+	m := &Member{
+		Key:   &CSTToken{Token: Token{Type: TokenString, Value: key}, Raw: string(AppendString(nil, key)), Leading: leading},
+		Colon: &CSTToken{Token: Token{Type: TokenColon, Value: ":"}, Raw: ":", Trailing: sep},
+	}
+	var err error
+	if m.Value, err = c.render(v, c.itemIndent(n, leading)); err != nil {
+		return err
+	}
+	if count := len(n.Members); count > 0 {
+		prev := n.Members[count-1]
+		if prev.Comma == nil {
+			prev.Comma = newComma(prev.Value)
+		} else {
+			// keep the trailing comma style of the input
+			m.Comma = &CSTToken{Token: prev.Comma.Token, Raw: ","}
+		}
+	}
+	n.Members = append(n.Members, m)
+	return nil
+}
+
+func (c *CST) appendElement(n *Node, v any) error {
+	leading, _ := c.itemLayout(n)
+	if count := len(n.Elements); count > 0 {
+		prev := n.Elements[count-1]
+		leading = c.breakAfterComment(leading, prev.Value.Open, prev.Comma, prev.Value)
+	}
+	node, err := c.render(v, c.itemIndent(n, leading))
+	if err != nil {
+		return err
+	}
+	node.Open.Leading = leading
+	e := &Element{Value: node}
+	if count := len(n.Elements); count > 0 {
+		prev := n.Elements[count-1]
+		if prev.Comma == nil {
+			prev.Comma = newComma(prev.Value)
+		} else {
+			e.Comma = &CSTToken{Token: prev.Comma.Token, Raw: ","}
+		}
+	}
+	n.Elements = append(n.Elements, e)
+	return nil
+}
+
+// newComma creates the comma that goes after value. Trivia trailing the
+// value (such as a // comment) moves behind the comma so it cannot swallow it.
+func newComma(value *Node) *CSTToken {
+	last := value.lastToken()
+	comma := &CSTToken{Token: Token{Type: TokenComma, Value: ","}, Raw: ",", Trailing: last.Trailing}
+	last.Trailing = ""
+	return comma
+}
+
+// breakAfterComment returns the leading trivia for an item following the
+// last one, which starts with first and ends with value and comma. When the
+// trivia after that item ends in a // comment, the new item would be written
+// into the comment, so it goes on a new line indented like its sibling.
+func (c *CST) breakAfterComment(leading string, first, comma *CSTToken, value *Node) string {
+	trivia := value.lastToken().Trailing
+	if comma != nil {
+		trivia = comma.Trailing
+	}
+	if strings.Contains(leading, "\n") || !endsInLineComment(trivia) {
+		return leading
+	}
+	return "\n" + c.lineIndent(first)
+}
+
+// endsInLineComment reports whether trivia ends inside a // comment.
+func endsInLineComment(trivia string) bool {
+	inLine := false
+	for i := 0; i < len(trivia); i++ {
+		switch {
+		case inLine:
+			inLine = trivia[i] != '\n'
+		case strings.HasPrefix(trivia[i:], "//"):
+			inLine = true
+			i++
+		case strings.HasPrefix(trivia[i:], "/*"):
+			end := strings.Index(trivia[i+2:], "*/")
+			if end < 0 {
+				return false
+			}
+			i += end + 3
+		}
+	}
+	return inLine
+}
+
+// Delete removes the object member or array element at pointer.
+func (c *CST) Delete(pointer string) error {
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(p) == 0 {
+		return fmt.Errorf("cannot delete the document root")
+	}
+	parentPath, last := p.Parent()
+	parent, err := c.lookup(parentPath)
+	if err != nil {
+		return err
+	}
+	switch parent.Kind {
+	case NodeObject:
+		i, m := parent.member(last)
+		if m == nil {
+			return fmt.Errorf("json pointer %q: member %q not found", pointer, last)
+		}
+		if i == len(parent.Members)-1 && i > 0 && m.Comma == nil {
+			dropComma(&parent.Members[i-1].Comma, parent.Members[i-1].Value)
+		}
+		parent.Members = append(parent.Members[:i], parent.Members[i+1:]...)
+	case NodeArray:
+		i, err := arrayIndex(last, len(parent.Elements))
+		if err != nil {
+			return fmt.Errorf("json pointer %q: %w", pointer, err)
+		}
+		if i == len(parent.Elements)-1 && i > 0 && parent.Elements[i].Comma == nil {
+			dropComma(&parent.Elements[i-1].Comma, parent.Elements[i-1].Value)
+		}
+		parent.Elements = append(parent.Elements[:i], parent.Elements[i+1:]...)
+	default:
+		return fmt.Errorf("json pointer %q: cannot index into a scalar", pointer)
+	}
+	return nil
+}
+
+// dropComma removes the comma after value, which becomes the last item,
+// keeping any trivia that followed the comma.
+func dropComma(comma **CSTToken, value *Node) {
+	last := value.lastToken()
+	last.Trailing += (*comma).Leading + (*comma).Trailing
+	*comma = nil
+}
+
+// Rename changes the key of the object member at pointer.
+func (c *CST) Rename(pointer, newKey string) error {
+	p, err := ParsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(p) == 0 {
+		return fmt.Errorf("the document root has no key")
+	}
+	parentPath, last := p.Parent()
+	parent, err := c.lookup(parentPath)
+	if err != nil {
+		return err
+	}
+	if parent.Kind != NodeObject {
+		return fmt.Errorf("json pointer %q does not refer to an object member", pointer)
+	}
+	_, m := parent.member(last)
+	if m == nil {
+		return fmt.Errorf("json pointer %q: member %q not found", pointer, last)
+	}
+	if _, clash := parent.member(newKey); clash != nil && newKey != last {
+		return fmt.Errorf("member %q already exists", newKey)
+	}
+	m.Key.Token = Token{Type: TokenString, Value: newKey}
+	m.Key.Raw = string(AppendString(nil, newKey))
+	return nil
+}
+
+// render builds the tree for a new value. Multi-line documents get the
+// value pretty-printed with the document's indent unit, continuing from the
+// indentation of the line it is placed on.
+func (c *CST) render(v any, indent string) (*Node, error) {
+	var out []byte
+	var err error
+	if unit, ok := c.indentUnit(); ok {
+		out, err = MarshalIndent(v, indent, unit)
+	} else {
+		out, err = Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	tree, err := ParseCST(strings.NewReader(string(out)), JSON)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root, nil
+}
+
+// replaceNode puts repl where old was, keeping old's surrounding trivia.
+func replaceNode(old, repl *Node) *Node {
+	repl.Open.Leading = old.Open.Leading
+	repl.lastToken().Trailing = old.lastToken().Trailing
+	return repl
+}
+
+func (n *Node) lastToken() *CSTToken {
+	if n.Close != nil {
+		return n.Close
+	}
+	return n.Open
+}
+
+// indentUnit guesses the document's indentation step from the smallest
+// indentation found at the start of a line. ok is false for documents
+// written on a single line.
+func (c *CST) indentUnit() (string, bool) {
+	unit := ""
+	multiline := false
+	for _, t := range c.Tokens() {
+		for _, trivia := range []string{t.Leading, t.Trailing} {
+			i := strings.LastIndexByte(trivia, '\n')
+			if i < 0 {
+				continue
+			}
+			multiline = true
+			ind := indentOf(trivia[i:])
+			if ind != "" && (unit == "" || len(ind) < len(unit)) {
+				unit = ind
+			}
+		}
+	}
+	if !multiline {
+		return "", false
+	}
+	if unit == "" {
+		unit = "  "
+	}
+	return unit, true
+}
+
+// itemLayout returns the leading trivia for a new member or element of n and
+// the separator to put after its colon, modelled on the existing items.
+func (c *CST) itemLayout(n *Node) (leading, sep string) {
+	// first is the first token of the current last item, prevComma the comma
+	// before it; on a single line the spacing between items lives there
+	var first, prevComma *CSTToken
+	switch count := len(n.Members); {
+	case count > 0:
+		m := n.Members[count-1]
+		first, sep = m.Key, m.Colon.Trailing+m.Value.Open.Leading
+		if count > 1 {
+			prevComma = n.Members[count-2].Comma
+		}
+	case len(n.Elements) > 0:
+		count := len(n.Elements)
+		first = n.Elements[count-1].Value.Open
+		if count > 1 {
+			prevComma = n.Elements[count-2].Comma
+		}
+	}
+	if first != nil {
+		if i := strings.LastIndexByte(first.Leading, '\n'); i >= 0 {
+			return "\n" + indentOf(first.Leading[i:]), leadingSpace(sep)
+		}
+		gap := first.Leading
+		if prevComma != nil {
+			gap = prevComma.Trailing + gap
+		}
+		return leadingSpace(gap), leadingSpace(sep)
+	}
+	// empty container: open it up if the document is multi-line
+	unit, ok := c.indentUnit()
+	if !ok {
+		return "", ""
+	}
+	outer := c.lineIndent(n.Open)
+	n.Close.Leading = "\n" + outer
+	return "\n" + outer + unit, " "
+}
+
+// itemIndent is the indentation of a new item of n with the given leading
+// trivia.
+func (c *CST) itemIndent(n *Node, leading string) string {
+	if strings.Contains(leading, "\n") {
+		return indentOf(leading)
+	}
+	return c.lineIndent(n.Open)
+}
+
+// leadingSpace drops comments from trivia that does not span lines.
+func leadingSpace(trivia string) string {
+	if strings.TrimSpace(trivia) != "" {
+		return " "
+	}
+	return trivia
+}
+
+// indentOf returns the run of spaces and tabs after the newline that starts
+// s, or at the start of s.
+func indentOf(s string) string {
+	s = strings.TrimPrefix(s, "\n")
+	end := 0
+	for end < len(s) && (s[end] == ' ' || s[end] == '\t') {
+		end++
+	}
+	return s[:end]
+}
+
+// lineIndent returns the indentation of the line t is on.
+func (c *CST) lineIndent(t *CSTToken) string {
+	indent := ""
+	track := func(trivia string) {
+		if i := strings.LastIndexByte(trivia, '\n'); i >= 0 {
+			indent = indentOf(trivia[i:])
+		}
+	}
+	for _, tok := range c.Tokens() {
+		track(tok.Leading)
+		if tok == t {
+			break
+		}
+		track(tok.Trailing)
+	}
+	return indent
+}
@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const editInput = `{
+    // release metadata
+    "name": "parsejj",
+    "version": "1.2.3", /* bumped by automation */
+    "tags": [
+        "json",
+        "parser"
+    ],
+    "inline": {"a": 1, "b": 2},
+    "empty": {}
+}
+`
+
+func editTree(t *testing.T, input string, d Dialect) *CST {
+	t.Helper()
+	cst, err := ParseCST(strings.NewReader(input), d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return cst
+}
+
+func TestCSTEdits(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     func(c *CST) error
+		expected string
+	}{
+		{
+			name:     "set scalar keeps comment",
+			edit:     func(c *CST) error { return c.Set("/version", "1.2.4") },
+			expected: strings.Replace(editInput, `"1.2.3"`, `"1.2.4"`, 1),
+		},
+		{
+			name:     "set nested inline",
+			edit:     func(c *CST) error { return c.Set("/inline/b", 3.0) },
+			expected: strings.Replace(editInput, `"b": 2`, `"b": 3`, 1),
+		},
+		{
+			name:     "set replaces container",
+			edit:     func(c *CST) error { return c.Set("/tags", []any{"x"}) },
+			expected: strings.Replace(editInput, "[\n        \"json\",\n        \"parser\"\n    ]", "[\n        \"x\"\n    ]", 1),
+		},
+		{
+			name:     "insert member",
+			edit:     func(c *CST) error { return c.InsertMember("", "license", "MIT") },
+			expected: strings.Replace(editInput, "\"empty\": {}\n}", "\"empty\": {},\n    \"license\": \"MIT\"\n}", 1),
+		},
+		{
+			name:     "insert member with object value",
+			edit:     func(c *CST) error { return c.Set("/author", map[string]any{"name": "r"}) },
+			expected: strings.Replace(editInput, "\"empty\": {}\n}", "\"empty\": {},\n    \"author\": {\n        \"name\": \"r\"\n    }\n}", 1),
+		},
+		{
+			name:     "insert into inline object",
+			edit:     func(c *CST) error { return c.InsertMember("/inline", "c", true) },
+			expected: strings.Replace(editInput, `{"a": 1, "b": 2}`, `{"a": 1, "b": 2, "c": true}`, 1),
+		},
+		{
+			name:     "insert into empty object",
+			edit:     func(c *CST) error { return c.InsertMember("/empty", "k", "v") },
+			expected: strings.Replace(editInput, `"empty": {}`, "\"empty\": {\n        \"k\": \"v\"\n    }", 1),
+		},
+		{
+			name:     "append element",
+			edit:     func(c *CST) error { return c.Set("/tags/-", "cst") },
+			expected: strings.Replace(editInput, "\"parser\"\n", "\"parser\",\n        \"cst\"\n", 1),
+		},
+		{
+			name:     "delete last element",
+			edit:     func(c *CST) error { return c.Delete("/tags/1") },
+			expected: strings.Replace(editInput, "\"json\",\n        \"parser\"\n", "\"json\"\n", 1),
+		},
+		{
+			name:     "delete first element",
+			edit:     func(c *CST) error { return c.Delete("/tags/0") },
+			expected: strings.Replace(editInput, "\n        \"json\",", "", 1),
+		},
+		{
+			name:     "delete member",
+			edit:     func(c *CST) error { return c.Delete("/inline/a") },
+			expected: strings.Replace(editInput, `{"a": 1, "b": 2}`, `{"b": 2}`, 1),
+		},
+		{
+			name:     "rename key",
+			edit:     func(c *CST) error { return c.Rename("/name", "title") },
+			expected: strings.Replace(editInput, `"name"`, `"title"`, 1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := editTree(t, editInput, JSONC)
+			if err := tt.edit(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := c.String(); got != tt.expected {
+				t.Fatalf("mismatch:\nwant\n%s\ngot\n%s", tt.expected, got)
+			}
+			if _, err := ParseCST(strings.NewReader(c.String()), JSONC); err != nil {
+				t.Fatalf("edited document no longer parses: %v", err)
+			}
+		})
+	}
+}
+
+func TestCSTEditCommentBeforeComma(t *testing.T) {
+	c := editTree(t, "{\n  \"a\": 1 // note\n}", JSONC)
+	if err := c.InsertMember("", "b", 2.0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "{\n  \"a\": 1, // note\n  \"b\": 2\n}"
+	if got := c.String(); got != expected {
+		t.Fatalf("mismatch:\nwant %q\ngot  %q", expected, got)
+	}
+}
+
+func TestCSTEditAfterSameLineComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		edit     func(c *CST) error
+		expected string
+		value    any
+	}{
+		{
+			name:     "insert member",
+			input:    "{\"a\": 1 // one\n}",
+			edit:     func(c *CST) error { return c.InsertMember("", "b", 2.0) },
+			expected: "{\"a\": 1, // one\n\"b\": 2\n}",
+			value:    map[string]any{"a": 1.0, "b": 2.0},
+		},
+		{
+			name:     "append element",
+			input:    "[1 // one\n]",
+			edit:     func(c *CST) error { return c.Set("/-", 2.0) },
+			expected: "[1, // one\n2\n]",
+			value:    []any{1.0, 2.0},
+		},
+		{
+			name:     "append after trailing comma",
+			input:    "[\n  [1, // one\n  ]\n]",
+			edit:     func(c *CST) error { return c.Set("/0/-", 2.0) },
+			expected: "[\n  [1, // one\n  2,\n  ]\n]",
+			value:    []any{[]any{1.0, 2.0}},
+		},
+		{
+			name:     "block comment does not break",
+			input:    "[1 /* // */]",
+			edit:     func(c *CST) error { return c.Set("/-", 2.0) },
+			expected: "[1, /* // */2]",
+			value:    []any{1.0, 2.0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := editTree(t, tt.input, JSONC)
+			if err := tt.edit(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := c.String(); got != tt.expected {
+				t.Fatalf("mismatch:\nwant %q\ngot  %q", tt.expected, got)
+			}
+			v, err := ParseDialect(strings.NewReader(c.String()), JSONC)
+			if err != nil || !Equal(v, tt.value) {
+				t.Fatalf("edited document parses to %#v, %v; want %#v", v, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestCSTEditKeepsTrailingCommaStyle(t *testing.T) {
+	c := editTree(t, "[\n  1,\n  2,\n]", JSONC)
+	if err := c.Set("/-", 3.0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "[\n  1,\n  2,\n  3,\n]"
+	if got := c.String(); got != expected {
+		t.Fatalf("mismatch:\nwant %q\ngot  %q", expected, got)
+	}
+}
+
+func TestCSTEditErrors(t *testing.T) {
+	c := editTree(t, editInput, JSONC)
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"set missing parent", c.Set("/nope/x", 1.0)},
+		{"set past end", c.Set("/tags/5", 1.0)},
+		{"insert existing", c.InsertMember("", "name", "x")},
+		{"insert into array", c.InsertMember("/tags", "k", 1.0)},
+		{"delete root", c.Delete("")},
+		{"delete missing", c.Delete("/missing")},
+		{"rename clash", c.Rename("/name", "version")},
+		{"rename element", c.Rename("/tags/0", "x")},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+	if c.String() != editInput {
+		t.Fatalf("failed edits modified the document:\n%s", c.String())
+	}
+}
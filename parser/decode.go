@@ -0,0 +1,258 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The helpers in this file let callers drive a Lexer directly, one token at a
+// time, instead of building a map[string]any first. Code produced by
+// cmd/jsondecoder is written against them.
+
+var tokenNames = [...]string{
+	TokenLeftBrace:    "'{'",
+	TokenRightBrace:   "'}'",
+	TokenLeftBracket:  "'['",
+	TokenRightBracket: "']'",
+	TokenString:       "string",
+	TokenNumber:       "number",
+	TokenTrue:         "true",
+	TokenFalse:        "false",
+	TokenNull:         "null",
+	TokenComma:        "','",
+	TokenColon:        "':'",
+	TokenEOF:          "end of input",
+	TokenIdentifier:   "identifier",
+}
+
+func (t TokenType) String() string {
+	if int(t) < len(tokenNames) {
+		return tokenNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
+// UnexpectedTokenError reports a token that is not valid at its position.
+type UnexpectedTokenError struct {
+	Got  Token
+	Want string
+}
+
+func (e *UnexpectedTokenError) Error() string {
+	if e.Got.Start.Line == 0 {
+		// the token did not come from a Lexer
+		return fmt.Sprintf("unexpected %v, expected %s", e.Got.Type, e.Want)
+	}
+	return fmt.Sprintf("unexpected %v, expected %s at line %d, column %d", e.Got.Type, e.Want, e.Got.Start.Line, e.Got.Start.Column)
+}
+
+// Expect reads the next token and fails unless it has type t.
+func (l *Lexer) Expect(t TokenType) (Token, error) {
+	tok, err := l.NextToken()
+	if err != nil {
+		return tok, err
+	}
+	if tok.Type != t {
+		return tok, &UnexpectedTokenError{Got: tok, Want: t.String()}
+	}
+	return tok, nil
+}
+
+// DecodeString returns the value of a string token.
+func DecodeString(tok Token) (string, error) {
+	if tok.Type != TokenString {
+		return "", &UnexpectedTokenError{Got: tok, Want: "string"}
+	}
+	return tok.Value, nil
+}
+
+// DecodeBool returns the value of a true or false token.
+func DecodeBool(tok Token) (bool, error) {
+	switch tok.Type {
+	case TokenTrue:
+		return true, nil
+	case TokenFalse:
+		return false, nil
+	}
+	return false, &UnexpectedTokenError{Got: tok, Want: "boolean"}
+}
+
+// DecodeFloat parses a number token as a float64.
+func DecodeFloat(tok Token) (float64, error) {
+	if tok.Type != TokenNumber {
+		return 0, &UnexpectedTokenError{Got: tok, Want: "number"}
+	}
+	return parseNumber(tok.Value)
+}
+
+// DecodeInt parses a number token as a signed integer of the given size.
+func DecodeInt(tok Token, bitSize int) (int64, error) {
+	if tok.Type != TokenNumber {
+		return 0, &UnexpectedTokenError{Got: tok, Want: "number"}
+	}
+	return strconv.ParseInt(tok.Value, 10, bitSize)
+}
+
+// DecodeUint parses a number token as an unsigned integer of the given size.
+func DecodeUint(tok Token, bitSize int) (uint64, error) {
+	if tok.Type != TokenNumber {
+		return 0, &UnexpectedTokenError{Got: tok, Want: "number"}
+	}
+	return strconv.ParseUint(tok.Value, 10, bitSize)
+}
+
+// DecodeArray walks the array opened by tok, calling elem with the first
+// token of every element. elem must consume the whole element.
+func DecodeArray(l *Lexer, tok Token, elem func(l *Lexer, tok Token) error) error {
+	if tok.Type != TokenLeftBracket {
+		return &UnexpectedTokenError{Got: tok, Want: "'['"}
+	}
+	if err := l.enter(tok); err != nil {
+		return err
+	}
+	defer l.leave()
+	for first := true; ; first = false {
+		tok, err := l.NextToken()
+		if err != nil {
+			return err
+		}
+		if tok.Type == TokenRightBracket && (first || l.dialect != JSON) {
+			// the dialects allow a trailing comma
+			return nil
+		}
+		if err := elem(l, tok); err != nil {
+			return err
+		}
+		if tok, err = l.NextToken(); err != nil {
+			return err
+		}
+		switch tok.Type {
+		case TokenComma:
+		case TokenRightBracket:
+			return nil
+		default:
+			return &UnexpectedTokenError{Got: tok, Want: "',' or ']'"}
+		}
+	}
+}
+
+// DecodeObject walks the object opened by tok, calling member with each key
+// and the first token of its value. member must consume the whole value.
+func DecodeObject(l *Lexer, tok Token, member func(l *Lexer, key string, tok Token) error) error {
+	if tok.Type != TokenLeftBrace {
+		return &UnexpectedTokenError{Got: tok, Want: "'{'"}
+	}
+	if err := l.enter(tok); err != nil {
+		return err
+	}
+	defer l.leave()
+	for first := true; ; first = false {
+		l.key = true
+		tok, err := l.NextToken()
+		l.key = false
+		if err != nil {
+			return err
+		}
+		if tok.Type == TokenRightBrace && (first || l.dialect != JSON) {
+			return nil
+		}
+		if !isKey(l.dialect, tok) {
+			return &UnexpectedTokenError{Got: tok, Want: "object key"}
+		}
+		key := tok.Value
+		if _, err := l.Expect(TokenColon); err != nil {
+			return err
+		}
+		if tok, err = l.NextToken(); err != nil {
+			return err
+		}
+		if err := member(l, key, tok); err != nil {
+			return err
+		}
+		if tok, err = l.NextToken(); err != nil {
+			return err
+		}
+		switch tok.Type {
+		case TokenComma:
+		case TokenRightBrace:
+			return nil
+		default:
+			return &UnexpectedTokenError{Got: tok, Want: "',' or '}'"}
+		}
+	}
+}
+
+// MaxDepth is the deepest nesting of arrays and objects DecodeArray and
+// DecodeObject accept, the same limit as encoding/json. It bounds the stack
+// used by the recursive decoders on hostile input.
+const MaxDepth = 10000
+
+// enter records that the container opened by tok is being decoded.
+func (l *Lexer) enter(tok Token) error {
+	if l.depth++; l.depth > MaxDepth {
+		l.depth--
+		return &SyntaxError{Msg: fmt.Sprintf("nesting deeper than %d", MaxDepth), Offset: tok.Start.Offset}
+	}
+	return nil
+}
+
+func (l *Lexer) leave() { l.depth-- }
+
+// isKey reports whether tok can name an object member. JSON5 identifiers that
+// happen to be keywords (true, null, NaN, ...) are valid keys as well.
+func isKey(d Dialect, tok Token) bool {
+	switch tok.Type {
+	case TokenString:
+		return true
+	case TokenIdentifier, TokenTrue, TokenFalse, TokenNull:
+		return d == JSON5
+	case TokenNumber:
+		return d == JSON5 && (tok.Value == "Infinity" || tok.Value == "NaN")
+	}
+	return false
+}
+
+// DecodeAny decodes the value starting at tok into the same representation
+// BasicParase produces.
+func DecodeAny(l *Lexer, tok Token) (any, error) {
+	switch tok.Type {
+	case TokenLeftBrace:
+		obj := make(map[string]any)
+		err := DecodeObject(l, tok, func(l *Lexer, key string, tok Token) error {
+			v, err := DecodeAny(l, tok)
+			obj[key] = v
+			return err
+		})
+		return obj, err
+	case TokenLeftBracket:
+		arr := []any{}
+		err := DecodeArray(l, tok, func(l *Lexer, tok Token) error {
+			v, err := DecodeAny(l, tok)
+			arr = append(arr, v)
+			return err
+		})
+		return arr, err
+	case TokenNumber:
+		return DecodeFloat(tok)
+	case TokenString, TokenTrue, TokenFalse, TokenNull:
+		return parseLiteral(tok), nil
+	default:
+		return nil, &UnexpectedTokenError{Got: tok, Want: "value"}
+	}
+}
+
+// SkipValue consumes the value starting at tok without building it.
+func SkipValue(l *Lexer, tok Token) error {
+	switch tok.Type {
+	case TokenLeftBrace:
+		return DecodeObject(l, tok, func(l *Lexer, _ string, tok Token) error {
+			return SkipValue(l, tok)
+		})
+	case TokenLeftBracket:
+		return DecodeArray(l, tok, SkipValue)
+	case TokenString, TokenNumber, TokenTrue, TokenFalse, TokenNull:
+		return nil
+	default:
+		return &UnexpectedTokenError{Got: tok, Want: "value"}
+	}
+}
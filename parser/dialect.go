@@ -0,0 +1,331 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Dialect selects which extensions to RFC 8259 JSON a Lexer accepts.
+type Dialect int
+
+const (
+	// JSON is strict standard JSON, the default.
+	JSON Dialect = iota
+	// JSON5 accepts the full JSON5 grammar (https://spec.json5.org):
+	// comments, trailing commas, identifier keys, single-quoted and
+	// multi-line strings, hex numbers, leading or trailing decimal points,
+	// explicit plus signs, Infinity and NaN.
+	JSON5
+	// JSONC is JSON with // and /* */ comments and optional trailing commas,
+	// as used by many editor and tool configuration files.
+	JSONC
+)
+
+// SyntaxError describes malformed input at a byte offset.
+type SyntaxError struct {
+	Msg    string
+	Offset int // offset of the first byte of the offending construct
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Msg, e.Offset)
+}
+
+// ParseDialect parses a single document written in dialect d. Unlike
+// BasicParase it reports syntax errors, including trailing data after the
+// document.
+func ParseDialect(r io.Reader, d Dialect) (any, error) {
+	l := getLexer(r, d)
+	defer putLexer(l)
+	return parseDocument(l)
+}
+
+// parseDocument decodes the single value l holds.
+func parseDocument(l *Lexer) (any, error) {
+	tok, err := l.NextToken()
+	if err != nil {
+		return nil, err
+	}
+	v, err := DecodeAny(l, tok)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := l.Expect(TokenEOF); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// skipComment consumes a // or /* */ comment; the leading '/' has already
+// been read.
+func (l *Lexer) skipComment() error {
+	start := l.pos - 1
+	char, err := l.next()
+	if err != nil {
+		return &SyntaxError{Msg: "unexpected character: /", Offset: start}
+	}
+	switch char {
+	case '/':
+		for {
+			char, err := l.next()
+			if err != nil || char == '\n' {
+				return nil
+			}
+		}
+	case '*':
+		for prev := byte(0); ; prev = char {
+			if char, err = l.next(); err != nil {
+				return &SyntaxError{Msg: "unterminated block comment", Offset: start}
+			}
+			if prev == '*' && char == '/' {
+				return nil
+			}
+		}
+	default:
+		return &SyntaxError{Msg: "unexpected character: /", Offset: start}
+	}
+}
+
+// lexJSON5 handles the tokens that only exist in JSON5. ok is false when
+// char starts an ordinary JSON token.
+func (l *Lexer) lexJSON5(char byte) (tok Token, ok bool, err error) {
+	switch {
+	case char == '\'':
+		tok, err = l.lexQuoted('\'')
+		return tok, true, err
+	case char == '+' || char == '-' || char == '.' || (char >= '0' && char <= '9'):
+		l.unread()
+		tok, err = l.lexNumber5()
+		return tok, true, err
+	case isIdentStart(char):
+		l.unread()
+		tok, err = l.lexIdentifier()
+		return tok, true, err
+	case char >= utf8.RuneSelf:
+		if r, _ := l.decodeRune(char); !isIdentRune(r, true) {
+			break
+		}
+		l.unread()
+		tok, err = l.lexIdentifier()
+		return tok, true, err
+	}
+	return Token{}, false, nil
+}
+
+func isIdentStart(char byte) bool {
+	return char == '$' || char == '_' || char == '\\' ||
+		(char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isIdentPart(char byte) bool {
+	return isIdentStart(char) || (char >= '0' && char <= '9')
+}
+
+// The runes outside ASCII that ECMAScript 5.1 allows to start and to
+// continue an identifier; U+200C and U+200D may continue one as well.
+var (
+	idStart    = []*unicode.RangeTable{unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl}
+	idContinue = append(idStart[:len(idStart):len(idStart)], unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+)
+
+// isIdentRune reports whether r may start an identifier, or with first
+// unset continue one. Invalid UTF-8, decoded as U+FFFD, may do neither.
+func isIdentRune(r rune, first bool) bool {
+	switch {
+	case r < utf8.RuneSelf && first:
+		return isIdentStart(byte(r))
+	case r < utf8.RuneSelf:
+		return isIdentPart(byte(r))
+	case first:
+		return unicode.In(r, idStart...)
+	}
+	return unicode.In(r, idContinue...) || r == '\u200c' || r == '\u200d'
+}
+
+// isSpace5 reports whether r is one of the whitespace runes JSON5 adds
+// outside ASCII: the space separators, which include U+00A0, the byte order
+// mark and the line and paragraph separators.
+func isSpace5(r rune) bool {
+	return unicode.Is(unicode.Zs, r) || r == '\ufeff' || r == '\u2028' || r == '\u2029'
+}
+
+// lexIdentifier reads a bare word. true, false and null keep their usual
+// token types, Infinity and NaN become numbers and anything else is an
+// unquoted object key.
+func (l *Lexer) lexIdentifier() (Token, error) {
+	var word []byte
+	for {
+		char, err := l.next()
+		if err != nil {
+			break
+		}
+		if char >= utf8.RuneSelf {
+			// invalid UTF-8 is left to the policy, other runes must fit
+			if r, size := l.decodeRune(char); (r != utf8.RuneError || size > 1) && !isIdentRune(r, len(word) == 0) {
+				l.unread()
+				break
+			}
+			if word, err = l.lexRune(word, char); err != nil {
+				return Token{}, err
+			}
+			continue
+		}
+		if !isIdentPart(char) {
+			l.unread()
+			break
+		}
+		if char == '\\' {
+			// identifiers may contain \uXXXX escapes
+			if c, err := l.next(); err != nil || c != 'u' {
+				return Token{}, fmt.Errorf("invalid escape in identifier")
+			}
+			r, err := l.lexHex(4)
+			if err != nil {
+				return Token{}, err
+			}
+			word = append(word, string(r)...)
+			continue
+		}
+		word = append(word, char)
+	}
+	switch string(word) {
+	case "true":
+		return Token{Type: TokenTrue, Value: "true"}, nil
+	case "false":
+		return Token{Type: TokenFalse, Value: "false"}, nil
+	case "null":
+		return Token{Type: TokenNull, Value: "null"}, nil
+	case "Infinity":
+		return Token{Type: TokenNumber, Value: "Infinity"}, nil
+	case "NaN":
+		return Token{Type: TokenNumber, Value: "NaN"}, nil
+	}
+	if s, ok := l.intern(word); ok {
+		return Token{Type: TokenIdentifier, Value: s}, nil
+	}
+	return Token{Type: TokenIdentifier, Value: string(word)}, nil
+}
+
+// lexNumber5 reads a JSON5 number, which may carry a sign, be hexadecimal
+// or be one of the signed forms of Infinity and NaN.
+func (l *Lexer) lexNumber5() (Token, error) {
+	var num []byte
+	char, err := l.next()
+	if err != nil {
+		return Token{}, err
+	}
+	if char == '+' || char == '-' {
+		num = append(num, char)
+		if char, err = l.next(); err != nil {
+			return Token{}, fmt.Errorf("unexpected end of input in number")
+		}
+		if char == '+' || char == '-' {
+			return Token{}, fmt.Errorf("invalid number: %s%c", num, char)
+		}
+	}
+	sign := len(num)
+	if char == 'I' || char == 'N' {
+		l.unread()
+		word, err := l.lexIdentifier()
+		if err != nil {
+			return Token{}, err
+		}
+		if word.Value != "Infinity" && word.Value != "NaN" {
+			return Token{}, fmt.Errorf("invalid number: %s%s", num, word.Value)
+		}
+		return Token{Type: TokenNumber, Value: string(num) + word.Value}, nil
+	}
+	l.unread()
+	hex := false
+	for {
+		char, err := l.next()
+		if err != nil {
+			break
+		}
+		if (char == 'x' || char == 'X') && len(num) > 0 && num[len(num)-1] == '0' {
+			hex = true
+		} else if !isNumberChar(char) && !(hex && isHexDigit(char)) {
+			l.unread()
+			break
+		}
+		num = append(num, char)
+	}
+	// like ECMAScript, JSON5 has no octal-looking leading zeros
+	if digits := num[sign:]; !hex && len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return Token{}, fmt.Errorf("invalid number: %s", num)
+	}
+	if _, err := parseNumber(string(num)); err != nil {
+		return Token{}, fmt.Errorf("invalid number: %s", num)
+	}
+	return Token{Type: TokenNumber, Value: string(num)}, nil
+}
+
+func isHexDigit(char byte) bool {
+	return (char >= '0' && char <= '9') || (char >= 'a' && char <= 'f') || (char >= 'A' && char <= 'F')
+}
+
+// lexEscape5 decodes the escape sequences JSON5 adds on top of JSON. char is
+// the byte following the backslash.
+func (l *Lexer) lexEscape5(str []byte, char byte) ([]byte, error) {
+	switch char {
+	case '\'':
+		return append(str, '\''), nil
+	case 'v':
+		return append(str, '\v'), nil
+	case '0':
+		return append(str, 0), nil
+	case 'x':
+		r, err := l.lexHex(2)
+		if err != nil {
+			return nil, err
+		}
+		return append(str, string(r)...), nil
+	case '\n':
+		// line continuation
+		return str, nil
+	case '\r':
+		if next, err := l.peek(1); err == nil && next[0] == '\n' {
+			_, _ = l.next()
+		}
+		return str, nil
+	case 0xe2:
+		// U+2028 and U+2029 are line terminators too
+		if next, err := l.peek(2); err == nil && next[0] == 0x80 && (next[1] == 0xa8 || next[1] == 0xa9) {
+			_, _ = l.next()
+			_, _ = l.next()
+			return str, nil
+		}
+	}
+	if char >= '1' && char <= '9' {
+		return nil, fmt.Errorf("invalid escape character: %c", char)
+	}
+	if char >= utf8.RuneSelf {
+		// a rune outside ASCII escapes to itself as a whole
+		return l.lexRune(str, char)
+	}
+	// any other character escapes to itself
+	return append(str, char), nil
+}
+
+// parseNumber converts a number token to a float64, accepting the JSON5
+// hexadecimal, Infinity and NaN spellings as well as plain JSON numbers.
+func parseNumber(s string) (float64, error) {
+	body := s
+	if len(body) > 0 && (body[0] == '+' || body[0] == '-') {
+		body = body[1:]
+	}
+	if len(body) > 1 && body[0] == '0' && (body[1] == 'x' || body[1] == 'X') {
+		n, err := strconv.ParseUint(body[2:], 16, 64)
+		if err != nil {
+			return 0, err
+		}
+		if s[0] == '-' {
+			return -float64(n), nil
+		}
+		return float64(n), nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,254 @@
+package parser
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseJSON5(t *testing.T) {
+	input := `// the example from json5.org
+{
+  // comments
+  unquoted: 'and you can quote me on that',
+  singleQuotes: 'I can use "double quotes" here',
+  lineBreaks: "Look, Mom! \
+No \\n's!",
+  hexadecimal: 0xdecaf,
+  leadingDecimalPoint: .8675309, andTrailing: 8675309.,
+  positiveSign: +1,
+  trailingComma: 'in objects', andIn: ['arrays',],
+  "backwardsCompatible": "with JSON",
+  /* block
+     comment */ $dollar_key: -0x10,
+  null: 'keyword key',
+}`
+	got, err := ParseDialect(strings.NewReader(input), JSON5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"unquoted":            "and you can quote me on that",
+		"singleQuotes":        `I can use "double quotes" here`,
+		"lineBreaks":          `Look, Mom! No \n's!`,
+		"hexadecimal":         float64(0xdecaf),
+		"leadingDecimalPoint": .8675309,
+		"andTrailing":         8675309.0,
+		"positiveSign":        1.0,
+		"trailingComma":       "in objects",
+		"andIn":               []any{"arrays"},
+		"backwardsCompatible": "with JSON",
+		"$dollar_key":         -16.0,
+		"null":                "keyword key",
+	}
+	if !Equal(got, expected) {
+		t.Fatalf("mismatch:\nexpected %#v\ngot      %#v", expected, got)
+	}
+}
+
+func TestParseJSON5Special(t *testing.T) {
+	got, err := ParseDialect(strings.NewReader(`[Infinity, -Infinity, +Infinity, NaN, 'a\'b\x41B\0\v']`), JSON5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	arr := got.([]any)
+	if !math.IsInf(arr[0].(float64), 1) || !math.IsInf(arr[1].(float64), -1) || !math.IsInf(arr[2].(float64), 1) {
+		t.Errorf("infinities mismatch: %v", arr[:3])
+	}
+	if !math.IsNaN(arr[3].(float64)) {
+		t.Errorf("expected NaN, got %v", arr[3])
+	}
+	if arr[4] != "a'bAB\x00\v" {
+		t.Errorf("escapes mismatch: %q", arr[4])
+	}
+}
+
+func TestParseJSON5Zeros(t *testing.T) {
+	got, err := ParseDialect(strings.NewReader(`[0, -0, +0.5, 0., 0e1, 0x0F, 10]`), JSON5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []any{0.0, 0.0, 0.5, 0.0, 0.0, 15.0, 10.0}; !Equal(got, expected) {
+		t.Fatalf("mismatch:\nexpected %#v\ngot      %#v", expected, got)
+	}
+}
+
+func TestParseDialectErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		dialect Dialect
+	}{
+		{"json trailing comma", `[1,2,]`, JSON},
+		{"json comment", `{"a":1} // note`, JSON},
+		{"json single quotes", `['a']`, JSON},
+		{"json unquoted key", `{a:1}`, JSON},
+		{"json5 unterminated comment", `{a:1 /* oops`, JSON5},
+		{"json5 bad word", `[Infinit]`, JSON5},
+		{"json5 bad hex", `0xZZ`, JSON5},
+		{"json5 octal escape", `'\1'`, JSON5},
+		{"json5 double comma", `[1,,2]`, JSON5},
+		{"json5 leading zero", `01`, JSON5},
+		{"json5 leading zeros", `[00, 007]`, JSON5},
+		{"json5 signed leading zero", `-01`, JSON5},
+		{"json5 leading zero fraction", `+00.5`, JSON5},
+		{"json5 double sign hex", `+-0x10`, JSON5},
+		{"json5 double minus hex", `--0x1`, JSON5},
+		{"json5 double sign", `[-+1]`, JSON5},
+		{"json5 double sign infinity", `--Infinity`, JSON5},
+		{"json5 sign after hex prefix", `0x-1`, JSON5},
+		{"trailing data", `{} {}`, JSON5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, err := ParseDialect(strings.NewReader(tt.input), tt.dialect); err == nil {
+				t.Fatalf("expected error, got %#v", v)
+			}
+		})
+	}
+}
+
+func TestParseNumberSigns(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+		ok    bool
+	}{
+		{"-0x10", -16, true},
+		{"+0x10", 16, true},
+		{"-1.5", -1.5, true},
+		{"+-0x10", 0, false},
+		{"--0x1", 0, false},
+		{"-+1", 0, false},
+		{"++Infinity", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseNumber(tt.input)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Fatalf("%s: want %v (ok=%v) got %v, %v", tt.input, tt.want, tt.ok, got, err)
+		}
+	}
+}
+
+func TestParseJSON5Unicode(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  any // nil when the input is invalid
+	}{
+		{"no-break space", "{\u00a0a:1}", map[string]any{"a": float64(1)}},
+		{"line separator", "[1,\u20282]", []any{float64(1), float64(2)}},
+		{"paragraph separator", "[1\u2029]", []any{float64(1)}},
+		{"byte order mark", "\ufeff{a:1}", map[string]any{"a": float64(1)}},
+		{"ideographic space", "[\u3000true]", []any{true}},
+		{"letters", "{naïve:1,ça:2}", map[string]any{"naïve": float64(1), "ça": float64(2)}},
+		{"combining mark", "{e\u0301:1}", map[string]any{"e\u0301": float64(1)}},
+		{"zero width joiner", "{a\u200db:1}", map[string]any{"a\u200db": float64(1)}},
+		{"space ends identifier", "{a\u00a0:1}", map[string]any{"a": float64(1)}},
+		{"space splits identifier", "{a\u00a0b:1}", nil},
+		{"symbol key", "{€:1}", nil},
+		{"combining mark start", "{\u0301:1}", nil},
+		{"invalid UTF-8 key", "{\x80:1}", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, l := range map[string]*Lexer{
+				"reader": NewLexerDialect(strings.NewReader(tt.input), JSON5),
+				"bytes":  NewLexerBytes([]byte(tt.input), JSON5),
+			} {
+				got, err := parseDocument(l)
+				if tt.want == nil {
+					if err == nil {
+						t.Fatalf("%s: expected error, got %#v", name, got)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				}
+				if !Equal(got, tt.want) {
+					t.Fatalf("%s: want %#v got %#v", name, tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDialectStrictDefault(t *testing.T) {
+	got, err := ParseDialect(strings.NewReader(`{"name":"Alice","tags":["a","b"],"n":-1.5e2}`), JSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := runParser(`{"name":"Alice","tags":["a","b"],"n":-1.5e2}`); !Equal(got, want) {
+		t.Fatalf("strict parse disagrees with BasicParase:\nexpected %#v\ngot      %#v", want, got)
+	}
+}
+
+func TestLexJSON5Tokens(t *testing.T) {
+	l := NewLexerDialect(strings.NewReader(`{key: 'v', /* c */ n: +.5}`), JSON5)
+	expected := []Token{
+		{Type: TokenLeftBrace, Value: "{"},
+		{Type: TokenIdentifier, Value: "key"},
+		{Type: TokenColon, Value: ":"},
+		{Type: TokenString, Value: "v"},
+		{Type: TokenComma, Value: ","},
+		{Type: TokenIdentifier, Value: "n"},
+		{Type: TokenColon, Value: ":"},
+		{Type: TokenNumber, Value: "+.5"},
+		{Type: TokenRightBrace, Value: "}"},
+		{Type: TokenEOF},
+	}
+	for i, exp := range expected {
+		tok, err := l.NextToken()
+		if err != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, err)
+		}
+		if tok.Type != exp.Type || tok.Value != exp.Value {
+			t.Errorf("token %d: want %+v got %+v", i, exp, tok)
+		}
+	}
+}
+
+func TestParseJSONC(t *testing.T) {
+	input := `{
+	// editor settings
+	"editor.tabSize": 4, /* inline */
+	"files.exclude": {
+		"**/.git": true,
+		"**/node_modules": true, // trailing comma below
+	},
+	"recent": ["a.go", "b.go",],
+}`
+	got, err := ParseDialect(strings.NewReader(input), JSONC)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]any{
+		"editor.tabSize": 4.0,
+		"files.exclude":  map[string]any{"**/.git": true, "**/node_modules": true},
+		"recent":         []any{"a.go", "b.go"},
+	}
+	if !Equal(got, expected) {
+		t.Fatalf("mismatch:\nexpected %#v\ngot      %#v", expected, got)
+	}
+}
+
+func TestParseJSONCRejectsJSON5(t *testing.T) {
+	for _, input := range []string{`{a:1}`, `['x']`, `[0x10]`, `[.5]`, `[1,,]`} {
+		if v, err := ParseDialect(strings.NewReader(input), JSONC); err == nil {
+			t.Errorf("%s: expected error, got %#v", input, v)
+		}
+	}
+}
+
+func TestJSONCUnterminatedComment(t *testing.T) {
+	_, err := ParseDialect(strings.NewReader(`{"a": 1, /* never closed`), JSONC)
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("expected *SyntaxError, got %v", err)
+	}
+	if serr.Offset != 9 || !strings.Contains(serr.Error(), "unterminated block comment at offset 9") {
+		t.Fatalf("unexpected error: %v", serr)
+	}
+}
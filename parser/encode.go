@@ -0,0 +1,233 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Marshal encodes a parsed value back into compact JSON. Object members are
+// written in sorted key order so the output is deterministic. Invalid UTF-8
+// in strings is replaced with U+FFFD.
+func Marshal(v any) ([]byte, error) {
+	return MarshalOptions(v, EncodeOptions{})
+}
+
+// MarshalIndent is like Marshal but puts every array element and object
+// member on its own line, prefixed by prefix and indented by indent per
+// nesting level.
+func MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return MarshalOptions(v, EncodeOptions{Prefix: prefix, Indent: indent, Pretty: true})
+}
+
+// EncodeOptions configure MarshalOptions and AppendStringOptions.
+type EncodeOptions struct {
+	// Pretty puts every array element and object member on its own line,
+	// prefixed by Prefix and indented by Indent per nesting level.
+	Pretty         bool
+	Prefix, Indent string
+	// UTF8 is the policy for invalid UTF-8 in strings, the same the lexer
+	// applies; with UTF8Reject encoding fails.
+	UTF8 UTF8Policy
+	// ASCII escapes every rune outside ASCII as \uXXXX, runes outside the
+	// Basic Multilingual Plane as a surrogate pair, so that the output
+	// survives transports that are not 8-bit clean.
+	ASCII bool
+}
+
+// MarshalOptions is like Marshal with the layout and string escaping set by
+// opts.
+func MarshalOptions(v any, opts EncodeOptions) ([]byte, error) {
+	e := encoder{EncodeOptions: opts}
+	if err := e.encode(v, 0); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// AppendAny appends the compact encoding of a parsed value to dst. Values
+// that Marshal rejects are written as null.
+func AppendAny(dst []byte, v any) []byte {
+	e := encoder{}
+	if err := e.encode(v, 0); err != nil {
+		return append(dst, "null"...)
+	}
+	return append(dst, e.buf.Bytes()...)
+}
+
+type encoder struct {
+	EncodeOptions
+	buf bytes.Buffer
+}
+
+func (e *encoder) newline(depth int) {
+	if !e.Pretty {
+		return
+	}
+	e.buf.WriteByte('\n')
+	e.buf.WriteString(e.Prefix)
+	for i := 0; i < depth; i++ {
+		e.buf.WriteString(e.Indent)
+	}
+}
+
+func (e *encoder) encode(v any, depth int) error {
+	switch val := v.(type) {
+	case nil:
+		e.buf.WriteString("null")
+	case bool:
+		e.buf.WriteString(strconv.FormatBool(val))
+	case float64:
+		s, err := formatNumber(val)
+		if err != nil {
+			return err
+		}
+		e.buf.WriteString(s)
+	case int:
+		e.buf.WriteString(strconv.Itoa(val))
+	case string:
+		if err := e.string(val); err != nil {
+			return err
+		}
+	case []any:
+		e.buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			e.newline(depth + 1)
+			if err := e.encode(item, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(val) > 0 {
+			e.newline(depth)
+		}
+		e.buf.WriteByte(']')
+	case map[string]any:
+		e.buf.WriteByte('{')
+		for i, k := range sortedKeys(val) {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			e.newline(depth + 1)
+			if err := e.string(k); err != nil {
+				return err
+			}
+			e.buf.WriteByte(':')
+			if e.Pretty {
+				e.buf.WriteByte(' ')
+			}
+			if err := e.encode(val[k], depth+1); err != nil {
+				return err
+			}
+		}
+		if len(val) > 0 {
+			e.newline(depth)
+		}
+		e.buf.WriteByte('}')
+	default:
+		return fmt.Errorf("cannot encode value of type %T", v)
+	}
+	return nil
+}
+
+// formatNumber uses the shortest representation that round-trips, switching
+// to exponent form for very large or very small magnitudes.
+func formatNumber(f float64) (string, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", fmt.Errorf("cannot encode %v as JSON number", f)
+	}
+	abs := math.Abs(f)
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		return strconv.FormatFloat(f, 'e', -1, 64), nil
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+func (e *encoder) string(s string) error {
+	b, err := AppendStringOptions(e.buf.AvailableBuffer(), s, e.EncodeOptions)
+	e.buf.Write(b)
+	return err
+}
+
+// AppendString appends s to dst as a quoted JSON string, replacing invalid
+// UTF-8 with U+FFFD.
+func AppendString(dst []byte, s string) []byte {
+	dst, _ = AppendStringOptions(dst, s, EncodeOptions{})
+	return dst
+}
+
+// AppendStringOptions appends s to dst as a quoted JSON string escaped as
+// opts say. With opts.UTF8 set to UTF8Reject, invalid UTF-8 makes it fail
+// and return dst unchanged.
+func AppendStringOptions(dst []byte, s string, opts EncodeOptions) ([]byte, error) {
+	n := len(dst)
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\n':
+			dst = append(dst, `\n`...)
+		case '\r':
+			dst = append(dst, `\r`...)
+		case '\t':
+			dst = append(dst, `\t`...)
+		case '\b':
+			dst = append(dst, `\b`...)
+		case '\f':
+			dst = append(dst, `\f`...)
+		default:
+			switch {
+			case c < 0x20:
+				dst = appendEscape(dst, rune(c))
+			case c < utf8.RuneSelf:
+				dst = append(dst, c)
+			default:
+				r, size := utf8.DecodeRuneInString(s[i:])
+				if r == utf8.RuneError && size == 1 {
+					switch opts.UTF8 {
+					case UTF8Reject:
+						return dst[:n], fmt.Errorf("cannot encode invalid UTF-8 at byte %d of string", i)
+					case UTF8Pass:
+						dst = append(dst, c)
+						continue
+					}
+				}
+				switch {
+				case !opts.ASCII:
+					dst = utf8.AppendRune(dst, r)
+				case r > 0xffff:
+					r1, r2 := utf16.EncodeRune(r)
+					dst = appendEscape(appendEscape(dst, r1), r2)
+				default:
+					dst = appendEscape(dst, r)
+				}
+				i += size - 1
+			}
+		}
+	}
+	return append(dst, '"'), nil
+}
+
+// appendEscape appends r, at most U+FFFF, as a \uXXXX escape.
+func appendEscape(dst []byte, r rune) []byte {
+	return append(dst, '\\', 'u', hexDigits[r>>12&0xf], hexDigits[r>>8&0xf], hexDigits[r>>4&0xf], hexDigits[r&0xf])
+}
+
+// AppendFloat appends f to dst as a JSON number. NaN and infinities have no
+// JSON representation and are written as null.
+func AppendFloat(dst []byte, f float64) []byte {
+	s, err := formatNumber(f)
+	if err != nil {
+		return append(dst, "null"...)
+	}
+	return append(dst, s...)
+}
@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{"null", nil, `null`},
+		{"bool", true, `true`},
+		{"integer", 30.0, `30`},
+		{"negative float", -12.5, `-12.5`},
+		{"large", 1e21, `1e+21`},
+		{"small", 0.0000001, `1e-07`},
+		{"string escapes", "a\"b\\c\nd\te\x01", `"a\"b\\c\nd\te\u0001"`},
+		{"unicode", "héllo", `"héllo"`},
+		{"empty array", []any(nil), `[]`},
+		{"sorted keys", map[string]any{"b": 1.0, "a": []any{"x", nil}}, `{"a":["x",null],"b":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("want %s got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMarshalIndent(t *testing.T) {
+	got, err := MarshalIndent(runParser(`{"b":[1,{}],"a":{"c":null}}`), "", "  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{
+  "a": {
+    "c": null
+  },
+  "b": [
+    1,
+    {}
+  ]
+}`
+	if string(got) != expected {
+		t.Errorf("want\n%s\ngot\n%s", expected, got)
+	}
+}
+
+func TestMarshalUnsupported(t *testing.T) {
+	if _, err := Marshal(struct{}{}); err == nil {
+		t.Fatalf("expected error for unsupported type")
+	}
+}
+
+func TestMarshalOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    any
+		opts     EncodeOptions
+		expected string // empty for an error
+	}{
+		{"replace", "a\x80b", EncodeOptions{}, "\"a\uFFFDb\""},
+		{"replace key", map[string]any{"\xff": 1.0}, EncodeOptions{}, "{\"\uFFFD\":1}"},
+		{"pass", "a\x80b", EncodeOptions{UTF8: UTF8Pass}, "\"a\x80b\""},
+		{"reject", "a\x80b", EncodeOptions{UTF8: UTF8Reject}, ""},
+		{"reject key", map[string]any{"\xe2\x82": 1.0}, EncodeOptions{UTF8: UTF8Reject}, ""},
+		{"reject valid", "héllo", EncodeOptions{UTF8: UTF8Reject}, `"héllo"`},
+		{"ascii", "héllo €", EncodeOptions{ASCII: true}, `"h\u00e9llo \u20ac"`},
+		{"ascii surrogate pair", "😀", EncodeOptions{ASCII: true}, `"\ud83d\ude00"`},
+		{"ascii replace", "\xc3", EncodeOptions{ASCII: true}, `"\ufffd"`},
+		{"ascii pass", "\xc3é", EncodeOptions{ASCII: true, UTF8: UTF8Pass}, "\"\xc3\\u00e9\""},
+		{"pretty", []any{"é"}, EncodeOptions{Pretty: true, Indent: "\t", ASCII: true}, "[\n\t\"\\u00e9\"\n]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalOptions(tt.input, tt.opts)
+			if tt.expected == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != tt.expected {
+				t.Errorf("want %q got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestAppendStringOptionsASCIIRoundTrip(t *testing.T) {
+	s := "tab\t \"q\" é € 😀 \u2028"
+	out, err := AppendStringOptions([]byte("x"), s, EncodeOptions{ASCII: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, c := range out {
+		if c >= 0x80 {
+			t.Fatalf("non-ASCII byte in %q", out)
+		}
+	}
+	tok, err := NewLexerBytes(out[1:], JSON).NextToken()
+	if err != nil || tok.Value != s {
+		t.Fatalf("decoded %q, %v; want %q", tok.Value, err, s)
+	}
+	if _, err := AppendStringOptions([]byte("x"), "\xff", EncodeOptions{UTF8: UTF8Reject}); err == nil {
+		t.Fatalf("expected an error for invalid UTF-8")
+	}
+}
+
+func TestMarshalRoundTripTestData(t *testing.T) {
+	for _, name := range []string{"albums", "posts", "todos", "users"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open("../test_data/example_" + name + ".json")
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			defer f.Close()
+			original := BasicParase(f)
+			out, err := Marshal(original)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if again := runParser(string(out)); !Equal(original, again) {
+				t.Errorf("round trip changed the document")
+			}
+		})
+	}
+}
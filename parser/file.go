@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+)
+
+// FileOptions configure OpenFile.
+type FileOptions struct {
+	Dialect Dialect
+	// Alias makes strings without escape sequences share memory with the
+	// mapped file instead of being copied. Such strings, keys included, are
+	// only valid until File.Close; copy any that must outlive it, e.g. with
+	// strings.Clone.
+	Alias bool
+	// UTF8 is the policy for invalid UTF-8 in strings.
+	UTF8 UTF8Policy
+}
+
+// File is a document parsed from a memory-mapped file.
+type File struct {
+	Value   any
+	release func() error
+}
+
+// ParseFile parses the JSON document in path. On Linux the file is
+// memory-mapped and lexed in place, so the OS pages it in as it is read
+// instead of it being copied through a buffer.
+func ParseFile(path string) (any, error) {
+	f, err := OpenFile(path, FileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return f.Value, f.Close()
+}
+
+// OpenFile parses the document in path like ParseFile. Unless opts.Alias is
+// set, the mapping is released before OpenFile returns.
+func OpenFile(path string, opts FileOptions) (*File, error) {
+	data, release, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	l := getBytesLexer(data, opts.Dialect)
+	l.alias = opts.Alias
+	l.utf8 = opts.UTF8
+	v, err := parseDocument(l)
+	putLexer(l)
+	var terr *UnexpectedTokenError
+	if errors.As(err, &terr) {
+		// the token may alias the mapping
+		terr.Got.Value = strings.Clone(terr.Got.Value)
+	}
+	if err != nil || !opts.Alias {
+		if rerr := release(); err == nil {
+			err = rerr
+		}
+		release = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &File{Value: v, release: release}, nil
+}
+
+// Close releases the mapping. Strings aliasing it must not be used
+// afterwards.
+func (f *File) Close() error {
+	if f.release == nil {
+		return nil
+	}
+	release := f.release
+	f.release = nil
+	return release()
+}
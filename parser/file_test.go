@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+)
+
+func TestNewLexerBytesMatchesReader(t *testing.T) {
+	inputs := []struct {
+		d   Dialect
+		src string
+	}{
+		{JSON, `{"a": "b\"c", "d": ["é😀", 1.5e3, true, null]}`},
+		{JSON, "[\"tab\\tnew\\nline\", \"héllo \U0001F600\"]"},
+		{JSON, `"unterminated`},
+		{JSON, `["\ud800 lone", "\x"]`},
+		{JSONC, "// c\n{\"a\": /* b */ 1,}"},
+		{JSON5, "{a: 'it\\'s', b: 'line\\\r\ncontinued', c: 0x1F, d: .5, e: Infinity}"},
+		{JSON5, "'\\ '"},
+	}
+	files, _ := filepath.Glob("../test_data/*.json")
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, struct {
+			d   Dialect
+			src string
+		}{JSON, string(data)})
+	}
+	for _, in := range inputs {
+		fromReader := NewLexerDialect(bytes.NewReader([]byte(in.src)), in.d)
+		fromBytes := NewLexerBytes([]byte(in.src), in.d)
+		for {
+			want, werr := fromReader.NextToken()
+			got, gerr := fromBytes.NextToken()
+			if got != want || (gerr == nil) != (werr == nil) || (gerr != nil && gerr.Error() != werr.Error()) {
+				t.Fatalf("%.40q: got %+v, %v; want %+v, %v", in.src, got, gerr, want, werr)
+			}
+			if werr != nil || want.Type == TokenEOF {
+				break
+			}
+		}
+	}
+}
+
+func TestLexerAlias(t *testing.T) {
+	data := []byte(`{"key": "value", "esc": "a\nb"}`)
+	l := NewLexerBytes(data, JSON)
+	l.alias = true
+	var strs []Token
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.Type == TokenEOF {
+			break
+		}
+		if tok.Type == TokenString {
+			strs = append(strs, tok)
+		}
+	}
+	if unsafe.StringData(strs[0].Value) != &data[2] || unsafe.StringData(strs[1].Value) != &data[9] {
+		t.Fatalf("strings without escapes are not aliased")
+	}
+	if strs[3].Value != "a\nb" || unsafe.StringData(strs[3].Value) == &data[25] {
+		t.Fatalf("string with escapes: got %q", strs[3].Value)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	files, _ := filepath.Glob("../test_data/*.json")
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := ParseDialect(bytes.NewReader(data), JSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ParseFile(file)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: ParseFile differs from ParseDialect, error %v", file, err)
+		}
+		f, err := OpenFile(file, FileOptions{Alias: true})
+		if err != nil || !reflect.DeepEqual(f.Value, want) {
+			t.Fatalf("%s: aliased parse differs from ParseDialect, error %v", file, err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatalf("second Close: %v", err)
+		}
+	}
+
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(empty); err == nil {
+		t.Fatalf("empty file parsed without error")
+	}
+	bad := filepath.Join(dir, "bad.json5")
+	if err := os.WriteFile(bad, []byte("{a: 1}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(bad); err == nil {
+		t.Fatalf("JSON5 parsed as JSON")
+	}
+	if f, err := OpenFile(bad, FileOptions{Dialect: JSON5, Alias: true}); err != nil || !reflect.DeepEqual(f.Value, map[string]any{"a": 1.0}) {
+		t.Fatalf("JSON5 file: got %v, %v", f, err)
+	}
+	if _, err := ParseFile(filepath.Join(dir, "missing.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing file: got error %v", err)
+	}
+}
+
+func BenchmarkParseFile(b *testing.B) {
+	const file = "../test_data/example_todos.json"
+	b.Run("ParseDialect", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			f, err := os.Open(file)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := ParseDialect(f, JSON); err != nil {
+				b.Fatal(err)
+			}
+			f.Close()
+		}
+	})
+	b.Run("ParseFile", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := ParseFile(file); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Alias", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			f, err := OpenFile(file, FileOptions{Alias: true})
+			if err != nil {
+				b.Fatal(err)
+			}
+			f.Close()
+		}
+	})
+}
@@ -0,0 +1,284 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Format pretty-prints the document with one member or element per line,
+// indented by indent per nesting level. Unlike MarshalIndent it keeps the
+// member order, the source spelling of every token and the comments; a
+// comment stays on its own line before the item it preceded, or at the end
+// of the line of the item it followed. Trailing commas are dropped.
+func (c *CST) Format(indent string) []byte {
+	f := formatter{indent: indent}
+	for _, comment := range commentsIn(c.Root.Open.Leading) {
+		f.buf.WriteString(comment)
+		f.buf.WriteByte('\n')
+	}
+	f.node(c.Root)
+	f.trailing(commentsIn(c.Root.lastToken().Trailing))
+	for _, comment := range commentsIn(c.EOF.Leading) {
+		f.buf.WriteByte('\n')
+		f.buf.WriteString(comment)
+	}
+	f.buf.WriteByte('\n')
+	return f.buf.Bytes()
+}
+
+type formatter struct {
+	buf    bytes.Buffer
+	indent string
+	depth  int
+	// lineComment is set after a // comment, which must end its line
+	lineComment bool
+}
+
+func (f *formatter) newline() {
+	f.buf.WriteByte('\n')
+	for i := 0; i < f.depth; i++ {
+		f.buf.WriteString(f.indent)
+	}
+	f.lineComment = false
+}
+
+// comments writes comments on lines of their own, starting on the current
+// (empty) line.
+func (f *formatter) comments(cs []string) {
+	for _, c := range cs {
+		f.buf.WriteString(c)
+		f.newline()
+	}
+}
+
+// trailing writes comments at the end of the current line.
+func (f *formatter) trailing(cs []string) {
+	for _, c := range cs {
+		if f.lineComment {
+			f.newline()
+		} else {
+			f.buf.WriteByte(' ')
+		}
+		f.buf.WriteString(c)
+		f.lineComment = strings.HasPrefix(c, "//")
+	}
+}
+
+func (f *formatter) node(n *Node) {
+	switch n.Kind {
+	case NodeObject:
+		items := make([]func(), len(n.Members))
+		for i, m := range n.Members {
+			items[i] = func() {
+				f.comments(commentsIn(m.Key.Leading))
+				f.buf.WriteString(m.Key.Raw)
+				f.buf.WriteString(": ")
+				f.node(m.Value)
+				if i < len(n.Members)-1 {
+					f.buf.WriteByte(',')
+				}
+				f.trailing(commentsIn(m.Key.Trailing, m.Colon.Leading, m.Colon.Trailing, m.Value.Open.Leading, m.Value.lastToken().Trailing, itemComma(m.Comma)))
+			}
+		}
+		f.container(n, items)
+	case NodeArray:
+		items := make([]func(), len(n.Elements))
+		for i, e := range n.Elements {
+			items[i] = func() {
+				f.comments(commentsIn(e.Value.Open.Leading))
+				f.node(e.Value)
+				if i < len(n.Elements)-1 {
+					f.buf.WriteByte(',')
+				}
+				f.trailing(commentsIn(e.Value.lastToken().Trailing, itemComma(e.Comma)))
+			}
+		}
+		f.container(n, items)
+	default:
+		f.buf.WriteString(n.Open.Raw)
+	}
+}
+
+func itemComma(comma *CSTToken) string {
+	if comma == nil {
+		return ""
+	}
+	return comma.Leading + comma.Trailing
+}
+
+func (f *formatter) container(n *Node, items []func()) {
+	inner := commentsIn(n.Open.Trailing)
+	closing := commentsIn(n.Close.Leading)
+	f.buf.WriteString(n.Open.Raw)
+	if len(items) == 0 && len(inner) == 0 && len(closing) == 0 {
+		f.buf.WriteString(n.Close.Raw)
+		return
+	}
+	f.trailing(inner)
+	f.depth++
+	for _, item := range items {
+		f.newline()
+		item()
+	}
+	if len(closing) > 0 {
+		f.newline()
+		f.comments(closing[:len(closing)-1])
+		f.buf.WriteString(closing[len(closing)-1])
+	}
+	f.depth--
+	f.newline()
+	f.buf.WriteString(n.Close.Raw)
+}
+
+// commentsIn returns the comments found in runs of trivia, in order.
+func commentsIn(trivia ...string) []string {
+	var out []string
+	for _, t := range trivia {
+		for i := 0; i < len(t); i++ {
+			if t[i] != '/' || i+1 >= len(t) {
+				continue
+			}
+			switch t[i+1] {
+			case '/':
+				end := strings.IndexByte(t[i:], '\n')
+				if end < 0 {
+					end = len(t) - i
+				}
+				out = append(out, strings.TrimRight(t[i:i+end], " \t\r"))
+				i += end
+			case '*':
+				end := strings.Index(t[i+2:], "*/")
+				if end < 0 {
+					end = len(t) - i - 4
+				}
+				out = append(out, t[i:i+end+4])
+				i += end + 3
+			}
+		}
+	}
+	return out
+}
+
+// Compact returns the document with whitespace, comments and trailing
+// commas removed. Tokens keep their source spelling, so the output is in
+// the dialect of the input.
+func (c *CST) Compact() []byte {
+	out, _ := c.Root.appendTo(nil, "", 0, false)
+	return out
+}
+
+// JSON converts the document to strict JSON in member order, indented by
+// indent per level or compact when indent is empty. Comments are dropped,
+// keys and strings are re-quoted and JSON5 numbers are written in decimal;
+// Infinity and NaN have no JSON spelling and are an error.
+func (c *CST) JSON(indent string) ([]byte, error) {
+	return c.Root.appendTo(nil, indent, 0, true)
+}
+
+func (n *Node) appendTo(dst []byte, indent string, depth int, strict bool) ([]byte, error) {
+	newline := func(depth int) {
+		if indent == "" {
+			return
+		}
+		dst = append(dst, '\n')
+		for i := 0; i < depth; i++ {
+			dst = append(dst, indent...)
+		}
+	}
+	var err error
+	switch n.Kind {
+	case NodeObject:
+		dst = append(dst, '{')
+		for i, m := range n.Members {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			newline(depth + 1)
+			if strict {
+				dst = AppendString(dst, m.Key.Value)
+			} else {
+				dst = append(dst, m.Key.Raw...)
+			}
+			dst = append(dst, ':')
+			if indent != "" {
+				dst = append(dst, ' ')
+			}
+			if dst, err = m.Value.appendTo(dst, indent, depth+1, strict); err != nil {
+				return nil, err
+			}
+		}
+		if len(n.Members) > 0 {
+			newline(depth)
+		}
+		return append(dst, '}'), nil
+	case NodeArray:
+		dst = append(dst, '[')
+		for i, e := range n.Elements {
+			if i > 0 {
+				dst = append(dst, ',')
+			}
+			newline(depth + 1)
+			if dst, err = e.Value.appendTo(dst, indent, depth+1, strict); err != nil {
+				return nil, err
+			}
+		}
+		if len(n.Elements) > 0 {
+			newline(depth)
+		}
+		return append(dst, ']'), nil
+	}
+	tok := n.Open
+	switch {
+	case !strict:
+		return append(dst, tok.Raw...), nil
+	case tok.Type == TokenString:
+		return AppendString(dst, tok.Value), nil
+	case tok.Type == TokenNumber && !isJSONNumber(tok.Raw):
+		f, err := parseNumber(tok.Value)
+		if err != nil {
+			return nil, err
+		}
+		s, err := formatNumber(f)
+		if err != nil {
+			return nil, &SyntaxError{Msg: fmt.Sprintf("%s cannot be represented in JSON", tok.Raw), Offset: tok.Start.Offset}
+		}
+		return append(dst, s...), nil
+	}
+	return append(dst, tok.Raw...), nil
+}
+
+// isJSONNumber reports whether s is spelled as RFC 8259 allows.
+func isJSONNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	switch n := digits(); {
+	case n == 0, n > 1 && s[i-n] == '0':
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCSTFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		dialect  Dialect
+		indent   string
+		expected string
+	}{
+		{
+			name:     "keeps order and spelling",
+			input:    `{"z":1.0,"a":[1,2,{}],"e":"\u00e9","m":{"x":[]}}`,
+			indent:   "  ",
+			expected: "{\n  \"z\": 1.0,\n  \"a\": [\n    1,\n    2,\n    {}\n  ],\n  \"e\": \"\\u00e9\",\n  \"m\": {\n    \"x\": []\n  }\n}\n",
+		},
+		{
+			name:     "scalar",
+			input:    "  true  ",
+			indent:   "\t",
+			expected: "true\n",
+		},
+		{
+			name: "comments",
+			input: `// settings
+{ // inline
+	/* before a */ "a": 1, // after a
+  "b": [1, // one
+  2,], /* after b */
+  // closing
+}
+// end`,
+			dialect: JSONC,
+			indent:  "  ",
+			expected: `// settings
+{ // inline
+  /* before a */
+  "a": 1, // after a
+  "b": [
+    1, // one
+    2
+  ] /* after b */
+  // closing
+}
+// end
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := ParseCST(strings.NewReader(tc.input), tc.dialect)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := string(c.Format(tc.indent))
+			if got != tc.expected {
+				t.Fatalf("format mismatch:\nexpected:\n%s\ngot:\n%s", tc.expected, got)
+			}
+			// formatting is idempotent and keeps the value
+			again, err := ParseCST(strings.NewReader(got), tc.dialect)
+			if err != nil {
+				t.Fatalf("formatted output does not parse: %v", err)
+			}
+			if string(again.Format(tc.indent)) != got {
+				t.Fatalf("formatting is not idempotent:\n%s", again.Format(tc.indent))
+			}
+			if !Equal(again.Root.Value(), c.Root.Value()) {
+				t.Fatalf("formatting changed the value")
+			}
+		})
+	}
+}
+
+func TestCSTFormatTestData(t *testing.T) {
+	data, err := os.ReadFile("../test_data/example_users.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	c, err := ParseCST(strings.NewReader(string(data)), JSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(c.Format("  ")); got != strings.TrimRight(string(data), "\n")+"\n" {
+		t.Fatalf("formatting an already formatted file changed it")
+	}
+}
+
+func TestCSTCompactAndJSON(t *testing.T) {
+	input := `// config
+{
+  name: 'app', // unquoted key
+  "z": [0x1F, +1, .5, 5., 1e3, -0.0,],
+  "nested": {"b": null, "a": true},
+}`
+	c, err := ParseCST(strings.NewReader(input), JSON5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, expected := string(c.Compact()), `{name:'app',"z":[0x1F,+1,.5,5.,1e3,-0.0],"nested":{"b":null,"a":true}}`; got != expected {
+		t.Errorf("compact mismatch:\nexpected %s\ngot      %s", expected, got)
+	}
+	got, err := c.JSON("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"name":"app","z":[31,1,0.5,5,1e3,-0.0],"nested":{"b":null,"a":true}}`; string(got) != expected {
+		t.Errorf("json mismatch:\nexpected %s\ngot      %s", expected, got)
+	}
+	got, err = c.JSON("  ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := ParseDialect(strings.NewReader(string(got)), JSON); err != nil {
+		t.Errorf("indented output is not strict JSON: %v\n%s", err, got)
+	}
+
+	c, err = ParseCST(strings.NewReader(`[NaN]`), JSON5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.JSON(""); err == nil {
+		t.Errorf("expected NaN to be rejected")
+	}
+}
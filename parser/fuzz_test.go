@@ -0,0 +1,232 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// maxSeed bounds the size of seeds; the fuzzer minimizes every new input
+// it finds interesting, which takes long for large ones.
+const maxSeed = 4 << 10
+
+// addSeeds seeds f with the fixtures and the JSONTestSuite cases. Inputs
+// found by the fuzzer are checked in under testdata/fuzz and run by go
+// test as regression tests.
+func addSeeds(f *testing.F) {
+	for _, pattern := range []string{"../test_data/*.json", filepath.Join(suiteDir, "*.json")} {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				f.Fatal(err)
+			}
+			if chunks, ok := splitArray(data, 1); ok && len(data) > maxSeed && len(chunks) > 1 {
+				// keep the first two records of a large array
+				data = append(append([]byte("["), data[chunks[0].start:chunks[1].end]...), ']')
+			}
+			if len(data) <= maxSeed {
+				f.Add(data)
+			}
+		}
+	}
+	for _, s := range []string{
+		`{"a": [1, 2.5e-3, -0, "xé😀", true, false, null]}`,
+		"// comment\n{a: 'b', c: +1, d: 0x1F, e: .5, f: [Infinity,],}",
+		`{"a": 1 /* c */, "b": 2,}`,
+	} {
+		f.Add([]byte(s))
+	}
+}
+
+// short formats v for a failure message, cut to a readable length.
+func short(v any) string {
+	s := fmt.Sprintf("%#v", v)
+	if len(s) > 200 {
+		s = s[:200] + "..."
+	}
+	return s
+}
+
+// lexAll returns the tokens of l up to EOF or the first error.
+func lexAll(t *testing.T, l *Lexer, limit int) ([]Token, error) {
+	var toks []Token
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			return toks, err
+		}
+		toks = append(toks, tok)
+		if tok.Type == TokenEOF {
+			return toks, nil
+		}
+		if len(toks) > limit {
+			t.Fatalf("more than %d tokens", limit)
+		}
+	}
+}
+
+// FuzzLexer checks that the reader and byte slice lexers agree on every
+// dialect, and that every token consumes input.
+func FuzzLexer(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, d := range []Dialect{JSON, JSONC, JSON5} {
+			// a token is at least one byte long, plus the final EOF
+			limit := len(data) + 1
+			want, werr := lexAll(t, NewLexerDialect(bytes.NewReader(data), d), limit)
+			got, gerr := lexAll(t, NewLexerBytes(data, d), limit)
+			if (werr == nil) != (gerr == nil) || !reflect.DeepEqual(got, want) {
+				t.Fatalf("dialect %d: lexers disagree:\nreader %v, %v\nbytes  %v, %v", d, want, werr, got, gerr)
+			}
+			for i, tok := range got {
+				if tok.End.Offset > len(data) || (tok.Type != TokenEOF && tok.End.Offset <= tok.Start.Offset) {
+					t.Fatalf("dialect %d: token %d %v spans [%d, %d)", d, i, tok.Type, tok.Start.Offset, tok.End.Offset)
+				}
+			}
+		}
+	})
+}
+
+// FuzzParse checks the strict JSON entry points against encoding/json: they
+// accept the same documents and decode the same values.
+func FuzzParse(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var want any
+		wantErr := json.Unmarshal(data, &want)
+		got, err := ParseDialect(bytes.NewReader(data), JSON)
+		if (err == nil) != (wantErr == nil) {
+			t.Fatalf("ParseDialect error %v, encoding/json error %v", err, wantErr)
+		}
+		if err == nil && !reflect.DeepEqual(got, want) {
+			t.Fatalf("ParseDialect decoded %s, encoding/json %s", short(got), short(want))
+		}
+
+		check := func(name string, v any, verr error) {
+			t.Helper()
+			if (verr == nil) != (err == nil) {
+				t.Fatalf("%s error %v, ParseDialect error %v", name, verr, err)
+			}
+			if verr == nil && !reflect.DeepEqual(v, got) {
+				t.Fatalf("%s decoded %s, ParseDialect %s", name, short(v), short(got))
+			}
+		}
+		v, verr := ParseDialectContext(context.Background(), bytes.NewReader(data), JSON)
+		check("ParseDialectContext", v, verr)
+		v, verr = parseDocument(NewLexerBytes(data, JSON))
+		check("NewLexerBytes", v, verr)
+
+		var a Arena
+		av, verr := a.Parse(data, JSON)
+		check("Arena", av.Interface(), verr)
+		// an invalid byte is replaced by the three of U+FFFD
+		if len(a.nodes) > len(data) || len(a.bytes) > 3*len(data) {
+			t.Fatalf("arena holds %d nodes and %d bytes for %d bytes of input", len(a.nodes), len(a.bytes), len(data))
+		}
+
+		v, _, verr = ParsePositions(bytes.NewReader(data), JSON)
+		check("ParsePositions", v, verr)
+
+		cst, verr := ParseCST(bytes.NewReader(data), JSON)
+		if verr == nil {
+			v = cst.Root.Value()
+			if s := cst.String(); s != string(data) {
+				t.Fatalf("CST prints %q, want the input back", s)
+			}
+		}
+		check("ParseCST", v, verr)
+
+		v, diags, verr := ParseRecover(bytes.NewReader(data), JSON)
+		for _, d := range diags {
+			if d.Severity == SeverityError && verr == nil {
+				verr = &SyntaxError{Msg: d.Message, Offset: d.Start}
+			}
+		}
+		check("ParseRecover", v, verr)
+
+		// outside strings, invalid UTF-8 is a syntax error anyway
+		v, verr = parseDocument(NewLexerBytes(data, JSON).WithUTF8(UTF8Reject))
+		if valid := err == nil && utf8.Valid(data); (verr == nil) != valid {
+			t.Fatalf("UTF8Reject error %v, want an error %v", verr, !valid)
+		}
+		if verr == nil && !reflect.DeepEqual(v, got) {
+			t.Fatalf("UTF8Reject decoded %s, ParseDialect %s", short(v), short(got))
+		}
+		if err == nil {
+			if _, err := MarshalOptions(got, EncodeOptions{UTF8: UTF8Reject}); err != nil {
+				t.Fatalf("Marshal of a replaced value: %v", err)
+			}
+		}
+	})
+}
+
+// FuzzDialects checks that the extended dialects accept every JSON document
+// with the same value, that ParseArrayParallelStrict agrees with
+// ParseDialect, and that the lenient entry points do not panic.
+func FuzzDialects(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		want, err := ParseDialect(bytes.NewReader(data), JSON)
+		for _, d := range []Dialect{JSONC, JSON5} {
+			got, derr := ParseDialect(bytes.NewReader(data), d)
+			if err == nil && (derr != nil || !reflect.DeepEqual(got, want)) {
+				t.Fatalf("dialect %d: got %s, %v for a JSON document decoding to %s", d, short(got), derr, short(want))
+			}
+			ParseRecover(bytes.NewReader(data), d)
+			ParseCST(bytes.NewReader(data), d)
+		}
+
+		basic := BasicParase(bytes.NewReader(data))
+		parallel := ParseArrayParallel(data, ParallelOptions{Workers: 2, ChunkSize: 16})
+		if err == nil && !reflect.DeepEqual(parallel, basic) {
+			t.Fatalf("ParseArrayParallel %s, BasicParase %s", short(parallel), short(basic))
+		}
+		strict, serr := ParseArrayParallelStrict(data, ParallelOptions{Workers: 2, ChunkSize: 16})
+		if (serr == nil) != (err == nil) || !reflect.DeepEqual(strict, want) {
+			t.Fatalf("ParseArrayParallelStrict %s, %v; ParseDialect %s, %v", short(strict), serr, short(want), err)
+		}
+		if out, err := StreamArray(data, ParallelOptions{Workers: 2, ChunkSize: 16}); err == nil {
+			for range out {
+			}
+		}
+		GenTokens(bytes.NewReader(data))
+	})
+}
+
+// TestMaxDepth checks that every strict entry point draws the nesting limit
+// where encoding/json does.
+func TestMaxDepth(t *testing.T) {
+	nested := func(depth int, open, close string) []byte {
+		return []byte(strings.Repeat(open, depth) + "1" + strings.Repeat(close, depth))
+	}
+	for _, depth := range []int{MaxDepth, MaxDepth + 1} {
+		for _, data := range [][]byte{nested(depth, "[", "]"), nested(depth, `{"a":`, "}")} {
+			var v any
+			want := json.Unmarshal(data, &v) == nil
+			if want != (depth <= MaxDepth) {
+				t.Fatalf("encoding/json accepts depth %d: %v", depth, want)
+			}
+			var a Arena
+			_, aerr := a.Parse(data, JSON)
+			_, perr := ParseDialect(bytes.NewReader(data), JSON)
+			_, _, serr := ParsePositions(bytes.NewReader(data), JSON)
+			_, cerr := ParseCST(bytes.NewReader(data), JSON)
+			for name, err := range map[string]error{"Arena": aerr, "ParseDialect": perr, "ParsePositions": serr, "ParseCST": cerr} {
+				if (err == nil) != want {
+					t.Fatalf("%s at depth %d of %.2s: error %v", name, depth, data, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package parser
+
+import "sync"
+
+// InternOptions bound the strings an Interner keeps.
+type InternOptions struct {
+	// MaxEntries is the number of distinct strings kept; once reached,
+	// further strings are looked up but not added. Zero means 4096.
+	MaxEntries int
+	// MaxLen is the length in bytes of the longest string kept; zero
+	// means 64.
+	MaxLen int
+	// Values interns string values no longer than MaxLen as well as
+	// object keys.
+	Values bool
+}
+
+const (
+	defaultInternEntries = 4096
+	defaultInternLen     = 64
+)
+
+// Interner hands out a single copy of the object keys, and optionally the
+// short string values, of the documents it is attached to. Keys like "id"
+// repeat in every record of a dataset; with an interner they are allocated
+// once instead of once per record.
+//
+// The parse functions intern keys with a private Interner per call. An
+// Interner built by NewSharedInterner can be shared between lexers running
+// concurrently, for instance one per worker of a pipeline.
+type Interner struct {
+	mu      *sync.RWMutex // nil unless shared
+	m       map[string]string
+	entries int
+	maxLen  int
+	values  bool
+}
+
+// NewInterner returns an Interner for use by one lexer at a time.
+func NewInterner(opts InternOptions) *Interner {
+	in := &Interner{entries: opts.MaxEntries, maxLen: opts.MaxLen, values: opts.Values}
+	if in.entries <= 0 {
+		in.entries = defaultInternEntries
+	}
+	if in.maxLen <= 0 {
+		in.maxLen = defaultInternLen
+	}
+	return in
+}
+
+// NewSharedInterner returns an Interner that is safe for concurrent use.
+func NewSharedInterner(opts InternOptions) *Interner {
+	in := NewInterner(opts)
+	in.mu = new(sync.RWMutex)
+	return in
+}
+
+// Intern returns the kept copy of s, keeping s if there is room.
+func (in *Interner) Intern(s string) string {
+	if t, ok := lookup(in, s); ok {
+		return t
+	}
+	return s
+}
+
+// Len returns the number of strings kept.
+func (in *Interner) Len() int {
+	if in.mu != nil {
+		in.mu.RLock()
+		defer in.mu.RUnlock()
+	}
+	return len(in.m)
+}
+
+// Reset forgets every string kept.
+func (in *Interner) Reset() {
+	if in.mu != nil {
+		in.mu.Lock()
+		defer in.mu.Unlock()
+	}
+	clear(in.m)
+}
+
+// lookup returns the kept copy of b, adding one if there is room; ok is
+// false for strings that are too long or do not fit. A string made from a
+// byte slice never shares memory with it.
+func lookup[T string | []byte](in *Interner, b T) (s string, ok bool) {
+	if len(b) > in.maxLen {
+		return "", false
+	}
+	if in.mu == nil {
+		if s, ok := in.m[string(b)]; ok {
+			return s, true
+		}
+		return add(in, b)
+	}
+	in.mu.RLock()
+	s, ok = in.m[string(b)]
+	in.mu.RUnlock()
+	if ok {
+		return s, true
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	// another lexer may have added b in the meantime
+	if s, ok := in.m[string(b)]; ok {
+		return s, true
+	}
+	return add(in, b)
+}
+
+func add[T string | []byte](in *Interner, b T) (string, bool) {
+	if len(in.m) >= in.entries {
+		return "", false
+	}
+	if in.m == nil {
+		in.m = make(map[string]string)
+	}
+	s := string(b)
+	in.m[s] = s
+	return s, true
+}
+
+// WithInterner makes l take object keys, and string values if in is
+// configured for them, from in. A nil in turns interning off. WithInterner
+// returns l.
+func (l *Lexer) WithInterner(in *Interner) *Lexer {
+	l.interner = in
+	return l
+}
+
+// intern returns the string for the contents b of a string token, or ok
+// false when l does not intern it.
+func (l *Lexer) intern(b []byte) (string, bool) {
+	in := l.interner
+	if in == nil || !(l.key || in.values) {
+		return "", false
+	}
+	return lookup(in, b)
+}
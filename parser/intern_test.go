@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+)
+
+// sameString reports whether a and b share their bytes.
+func sameString(a, b string) bool {
+	return len(a) == len(b) && unsafe.StringData(a) == unsafe.StringData(b)
+}
+
+// lexStrings returns the string and identifier tokens of input.
+func lexStrings(t *testing.T, l *Lexer, input string) []string {
+	t.Helper()
+	tok, err := l.NextToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	var walk func(l *Lexer, tok Token) error
+	walk = func(l *Lexer, tok Token) error {
+		switch tok.Type {
+		case TokenLeftBrace:
+			return DecodeObject(l, tok, func(l *Lexer, key string, tok Token) error {
+				out = append(out, key)
+				return walk(l, tok)
+			})
+		case TokenLeftBracket:
+			return DecodeArray(l, tok, walk)
+		case TokenString:
+			out = append(out, tok.Value)
+		}
+		return nil
+	}
+	if err := walk(l, tok); err != nil {
+		t.Fatalf("%q: %v", input, err)
+	}
+	return out
+}
+
+func TestInterner(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		input   string
+		opts    InternOptions
+		same    [][2]int // indexes of strings that must share memory
+		apart   [][2]int // equal strings that must not
+		kept    int
+	}{
+		{
+			name:  "keys",
+			input: `[{"id": "id"}, {"id": "id"}]`,
+			same:  [][2]int{{0, 2}},
+			apart: [][2]int{{1, 3}, {0, 1}},
+			kept:  1,
+		},
+		{
+			name:  "values",
+			input: `[{"id": "id"}, {"id": "id"}]`,
+			opts:  InternOptions{Values: true},
+			same:  [][2]int{{0, 1}, {0, 2}, {1, 3}},
+			kept:  1,
+		},
+		{
+			name:  "escaped key",
+			input: `[{"a\u0062": 1}, {"ab": 2}]`,
+			same:  [][2]int{{0, 1}},
+			kept:  1,
+		},
+		{
+			name:  "long keys are not kept",
+			input: `[{"abcdef": 1}, {"abcdef": 2}, {"abc": 3}, {"abc": 4}]`,
+			opts:  InternOptions{MaxLen: 3},
+			same:  [][2]int{{2, 3}},
+			apart: [][2]int{{0, 1}},
+			kept:  1,
+		},
+		{
+			name:  "full table",
+			input: `[{"aa": 1, "bb": 2}, {"aa": 1, "bb": 2}]`,
+			opts:  InternOptions{MaxEntries: 1},
+			same:  [][2]int{{0, 2}},
+			apart: [][2]int{{1, 3}},
+			kept:  1,
+		},
+		{
+			name:    "JSON5 identifiers",
+			dialect: JSON5,
+			input:   `[{id: 1, 'id': 2}, {id: 3}]`,
+			same:    [][2]int{{0, 1}, {0, 2}},
+			kept:    1,
+		},
+	}
+	for _, tt := range tests {
+		for _, fromBytes := range []bool{false, true} {
+			in := NewInterner(tt.opts)
+			l := NewLexerDialect(strings.NewReader(tt.input), tt.dialect)
+			if fromBytes {
+				l = NewLexerBytes([]byte(tt.input), tt.dialect)
+			}
+			got := lexStrings(t, l.WithInterner(in), tt.input)
+			for _, p := range tt.same {
+				if !sameString(got[p[0]], got[p[1]]) {
+					t.Fatalf("%s (bytes %v): strings %d and %d of %q are not shared", tt.name, fromBytes, p[0], p[1], got)
+				}
+			}
+			for _, p := range tt.apart {
+				if sameString(got[p[0]], got[p[1]]) {
+					t.Fatalf("%s (bytes %v): strings %d and %d of %q are shared", tt.name, fromBytes, p[0], p[1], got)
+				}
+			}
+			if in.Len() != tt.kept {
+				t.Fatalf("%s (bytes %v): %d strings kept, want %d", tt.name, fromBytes, in.Len(), tt.kept)
+			}
+		}
+	}
+}
+
+func TestInternerDoesNotAlias(t *testing.T) {
+	data := []byte(`{"key": 1}`)
+	in := NewInterner(InternOptions{})
+	l := NewLexerBytes(data, JSON).WithInterner(in)
+	l.alias = true
+	keys := lexStrings(t, l, string(data))
+	copy(data, `{"xxx": 1}`)
+	if keys[0] != "key" || in.Intern("key") != "key" {
+		t.Fatalf("interned key changed with its input: %q", keys[0])
+	}
+}
+
+func TestParseInternsKeys(t *testing.T) {
+	data, err := os.ReadFile("../test_data/example_todos.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := ParseDialect(bytes.NewReader(data), JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]string{}
+	for _, rec := range v.([]any) {
+		for k := range rec.(map[string]any) {
+			if first, ok := keys[k]; ok && !sameString(first, k) {
+				t.Fatalf("key %q allocated more than once", k)
+			}
+			keys[k] = k
+		}
+	}
+	want := runParser(string(data))
+	if !reflect.DeepEqual(v, want) {
+		t.Fatalf("interned parse differs from BasicParase")
+	}
+}
+
+func TestSharedInterner(t *testing.T) {
+	in := NewSharedInterner(InternOptions{MaxEntries: 50})
+	var wg sync.WaitGroup
+	results := make([][]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var b strings.Builder
+			b.WriteString("[")
+			for j := range 100 {
+				if j > 0 {
+					b.WriteString(",")
+				}
+				b.WriteString(`{"k` + string(rune('a'+j%26)) + `": 1}`)
+			}
+			b.WriteString("]")
+			l := NewLexer(strings.NewReader(b.String())).WithInterner(in)
+			results[i] = lexStrings(t, l, b.String())
+		}()
+	}
+	wg.Wait()
+	if in.Len() != 26 {
+		t.Fatalf("%d strings kept, want 26", in.Len())
+	}
+	for _, keys := range results[1:] {
+		for j, k := range keys {
+			if !sameString(k, results[0][j]) {
+				t.Fatalf("key %q not shared between lexers", k)
+			}
+		}
+	}
+	in.Reset()
+	if in.Len() != 0 {
+		t.Fatalf("Reset kept %d strings", in.Len())
+	}
+}
+
+func BenchmarkIntern(b *testing.B) {
+	data, err := os.ReadFile("../test_data/example_todos.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	parse := func(b *testing.B, in *Interner) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			l := NewLexerBytes(data, JSON).WithInterner(in)
+			tok, _ := l.NextToken()
+			if _, err := DecodeAny(l, tok); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	b.Run("None", func(b *testing.B) { parse(b, nil) })
+	b.Run("Keys", func(b *testing.B) { parse(b, NewInterner(InternOptions{})) })
+	b.Run("Values", func(b *testing.B) { parse(b, NewInterner(InternOptions{Values: true})) })
+	b.Run("Shared", func(b *testing.B) { parse(b, NewSharedInterner(InternOptions{})) })
+}
@@ -2,9 +2,14 @@ package parser
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
+	"strconv"
 	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
 )
 
 type TokenType int
@@ -22,44 +27,166 @@ const (
 	TokenComma                         // ,
 	TokenColon                         // :
 	TokenEOF                           //
+	TokenIdentifier                    // unquoted key, JSON5 only
 )
 
 type Token struct {
 	Type  TokenType
 	Value string
+	// Start is the position of the token's first byte and End the position
+	// just past its last one.
+	Start, End Position
 }
 type Lexer struct {
-	r   *bufio.Reader
-	pos int
+	r *bufio.Reader
+	// src replaces r for lexers over a byte slice; pos indexes it
+	src []byte
+	// alias makes strings without escapes share memory with src
+	alias   bool
+	pos     int
+	start   Position // position of the first byte of the current token
+	dialect Dialect
+	// line and the two column counts of pos, zero-based; prev holds them
+	// before the last byte read so unread can restore them
+	line, col, col16 int
+	prev             [3]int
+	// ctx is set by WithContext; tokens counts the calls to NextToken
+	ctx    context.Context
+	tokens int
+	// interner is set by WithInterner; key is set by DecodeObject while it
+	// reads a token that can only be a key
+	interner *Interner
+	key      bool
+	// depth counts the containers DecodeArray and DecodeObject are in
+	depth int
+	// utf8 is set by WithUTF8
+	utf8 UTF8Policy
 }
 
 func NewLexer(r io.Reader) *Lexer {
 	return &Lexer{r: bufio.NewReader(r)}
 }
 
+// NewLexerDialect returns a Lexer that accepts the extensions of dialect d.
+func NewLexerDialect(r io.Reader, d Dialect) *Lexer {
+	return &Lexer{r: bufio.NewReader(r), dialect: d}
+}
+
+// NewLexerBytes returns a Lexer reading data directly, without a
+// bufio.Reader in between.
+func NewLexerBytes(data []byte, d Dialect) *Lexer {
+	if data == nil {
+		data = []byte{}
+	}
+	return &Lexer{src: data, dialect: d}
+}
+
 func (l *Lexer) next() (byte, error) {
-	b, err := l.r.ReadByte()
-	if err != nil {
-		return 0, err
+	var b byte
+	if l.src != nil {
+		if l.pos >= len(l.src) {
+			return 0, io.EOF
+		}
+		b = l.src[l.pos]
+	} else {
+		var err error
+		if b, err = l.r.ReadByte(); err != nil {
+			return 0, err
+		}
 	}
 	l.pos++
+	l.prev = [3]int{l.line, l.col, l.col16}
+	switch {
+	case b == '\n':
+		l.line++
+		l.col, l.col16 = 0, 0
+	case b < 0x80 || b >= 0xc0:
+		// count the lead byte of every rune; runes outside the BMP take
+		// two UTF-16 code units
+		l.col++
+		l.col16++
+		if b >= 0xf0 {
+			l.col16++
+		}
+	}
 	return b, nil
 }
 
 func (l *Lexer) unread() {
-	_ = l.r.UnreadByte()
+	if l.src == nil {
+		_ = l.r.UnreadByte()
+	}
 	l.pos--
+	l.line, l.col, l.col16 = l.prev[0], l.prev[1], l.prev[2]
+}
+
+// peek returns the next n bytes without consuming them, or fewer and an
+// error at the end of the input.
+func (l *Lexer) peek(n int) ([]byte, error) {
+	if l.src == nil {
+		return l.r.Peek(n)
+	}
+	if rest := l.src[l.pos:]; len(rest) < n {
+		return rest, io.EOF
+	}
+	return l.src[l.pos : l.pos+n], nil
+}
+
+// position returns the Position of the next byte to be read.
+func (l *Lexer) position() Position {
+	return Position{Offset: l.pos, Line: l.line + 1, Column: l.col + 1, UTF16Column: l.col16 + 1}
 }
 
+// Span returns the byte offsets of the token most recently returned by
+// NextToken: start is its first byte and end is just past its last.
+func (l *Lexer) Span() (start, end int) {
+	return l.start.Offset, l.pos
+}
+
+// NextToken returns the next token, with its Start and End positions set.
 func (l *Lexer) NextToken() (Token, error) {
+	if l.ctx != nil {
+		if err := l.checkContext(); err != nil {
+			l.start = l.position()
+			return Token{Type: TokenEOF, Start: l.start, End: l.start}, err
+		}
+	}
+	tok, err := l.nextToken()
+	tok.Start, tok.End = l.start, l.position()
+	return tok, err
+}
+
+func (l *Lexer) nextToken() (Token, error) {
 	for {
+		start := l.position()
 		char, err := l.next()
 		if err == io.EOF {
+			l.start = l.position()
 			return Token{Type: TokenEOF}, nil
 		}
-		if unicode.IsSpace(rune(char)) {
+		if isSpace(char) || (l.dialect == JSON5 && (char == '\v' || char == '\f')) {
+			continue
+		}
+		if l.dialect == JSON5 && char >= utf8.RuneSelf {
+			if r, size := l.decodeRune(char); isSpace5(r) {
+				for range size - 1 {
+					l.next()
+				}
+				continue
+			}
+		}
+		l.start = start
+		if char == '/' && l.dialect != JSON {
+			if err := l.skipComment(); err != nil {
+				return Token{}, err
+			}
 			continue
 		}
+		if l.dialect == JSON5 {
+			if tok, ok, err := l.lexJSON5(char); ok {
+				return tok, err
+			}
+		}
 		switch char {
 		case '{':
 			return Token{Type: TokenLeftBrace, Value: "{"}, nil
@@ -94,32 +221,257 @@ func (l *Lexer) NextToken() (Token, error) {
 
 // "input text here"
 func (l *Lexer) lexString() (Token, error) {
+	return l.lexQuoted('"')
+}
+
+// lexQuoted reads a string closed by quote; the opening quote has already
+// been consumed.
+func (l *Lexer) lexQuoted(quote byte) (Token, error) {
+	open := l.pos - 1
 	var str []byte
+	if l.src != nil {
+		// take the string from src unless it has escapes
+		start := l.pos
+		for {
+			char, err := l.next()
+			if err == io.EOF {
+				return Token{}, &SyntaxError{Msg: "unterminated string", Offset: open}
+			}
+			if char == quote {
+				if s, ok := l.intern(l.src[start : l.pos-1]); ok {
+					return Token{Type: TokenString, Value: s}, nil
+				}
+				return Token{Type: TokenString, Value: l.text(start, l.pos-1)}, nil
+			}
+			if char == '\\' {
+				l.unread()
+				str = append(str, l.src[start:l.pos]...)
+				break
+			}
+			if l.isControl(char) {
+				return Token{}, &SyntaxError{Msg: "control character in string", Offset: l.pos - 1}
+			}
+			if char >= utf8.RuneSelf && l.utf8 != UTF8Pass && l.invalidUTF8() {
+				if l.utf8 == UTF8Reject {
+					return Token{}, &SyntaxError{Msg: "invalid UTF-8 in string", Offset: l.pos - 1}
+				}
+				// the replacement has to be built
+				l.unread()
+				str = append(str, l.src[start:l.pos]...)
+				break
+			}
+		}
+	}
 	for {
 		char, err := l.next()
-		if err == io.EOF || char == '"' {
+		if err == io.EOF {
+			return Token{}, &SyntaxError{Msg: "unterminated string", Offset: open}
+		}
+		if char == quote {
 			break
 		}
+		if char == '\\' {
+			if str, err = l.lexEscape(str); err != nil {
+				return Token{}, err
+			}
+			continue
+		}
+		if l.isControl(char) {
+			return Token{}, &SyntaxError{Msg: "control character in string", Offset: l.pos - 1}
+		}
+		if char >= utf8.RuneSelf {
+			if str, err = l.lexRune(str, char); err != nil {
+				return Token{}, err
+			}
+			continue
+		}
 		// keep appending char as long as theres chars and theres no enclosing quote
 		str = append(str, char)
 	}
+	if s, ok := l.intern(str); ok {
+		return Token{Type: TokenString, Value: s}, nil
+	}
 	return Token{Type: TokenString, Value: string(str)}, nil
 }
+
+// isControl reports whether char may not appear unescaped in a string:
+// any control character in JSON and JSONC, line terminators in JSON5.
+func (l *Lexer) isControl(char byte) bool {
+	if l.dialect == JSON5 {
+		return char == '\n' || char == '\r'
+	}
+	return char < 0x20
+}
+
+// text returns src[start:end] as a string, sharing memory with src when the
+// lexer aliases.
+func (l *Lexer) text(start, end int) string {
+	if l.alias && end > start {
+		return unsafe.String(&l.src[start], end-start)
+	}
+	return string(l.src[start:end])
+}
+
+// lexEscape decodes the escape sequence following a backslash and appends
+// the resulting bytes to str.
+func (l *Lexer) lexEscape(str []byte) ([]byte, error) {
+	char, err := l.next()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected end of input in string escape")
+	}
+	switch char {
+	case '"', '\\', '/':
+		return append(str, char), nil
+	case 'b':
+		return append(str, '\b'), nil
+	case 'f':
+		return append(str, '\f'), nil
+	case 'n':
+		return append(str, '\n'), nil
+	case 'r':
+		return append(str, '\r'), nil
+	case 't':
+		return append(str, '\t'), nil
+	case 'u':
+		r, err := l.lexHex(4)
+		if err != nil {
+			return nil, err
+		}
+		if utf16.IsSurrogate(r) {
+			// a high surrogate must be followed by an escaped low surrogate
+			r = l.lexLowSurrogate(r)
+		}
+		return utf8.AppendRune(str, r), nil
+	default:
+		if l.dialect == JSON5 {
+			return l.lexEscape5(str, char)
+		}
+		return nil, fmt.Errorf("invalid escape character: %c", char)
+	}
+}
+
+// lexHex reads n hex digits of an escape sequence.
+func (l *Lexer) lexHex(n int) (rune, error) {
+	var r rune
+	for i := 0; i < n; i++ {
+		char, err := l.next()
+		if err != nil {
+			return 0, fmt.Errorf("unexpected end of input in hex escape")
+		}
+		var d byte
+		switch {
+		case char >= '0' && char <= '9':
+			d = char - '0'
+		case char >= 'a' && char <= 'f':
+			d = char - 'a' + 10
+		case char >= 'A' && char <= 'F':
+			d = char - 'A' + 10
+		default:
+			return 0, fmt.Errorf("invalid hex digit in escape: %c", char)
+		}
+		r = r<<4 | rune(d)
+	}
+	return r, nil
+}
+
+// lexLowSurrogate combines a high surrogate with the \uXXXX that follows it.
+// Lone surrogates decode to U+FFFD.
+func (l *Lexer) lexLowSurrogate(high rune) rune {
+	peek, err := l.peek(6)
+	if err != nil || peek[0] != '\\' || peek[1] != 'u' {
+		return utf8.RuneError
+	}
+	low, err := strconv.ParseUint(string(peek[2:]), 16, 32)
+	if err != nil {
+		return utf8.RuneError
+	}
+	r := utf16.DecodeRune(high, rune(low))
+	if r == utf8.RuneError {
+		return r
+	}
+	for range peek {
+		_, _ = l.next()
+	}
+	return r
+}
 func (l *Lexer) lexNumber() (Token, error) {
+	start := l.pos
 	var strInt []byte
 	for {
 		char, err := l.next()
 		if err != nil {
 			break
 		}
-		if !unicode.IsDigit(rune(char)) && char != '.' && char != '-' {
+		if !isNumberChar(char) {
 			l.unread()
 			break
 		}
-		strInt = append(strInt, char)
+		if l.src == nil {
+			strInt = append(strInt, char)
+		}
+	}
+	var tok Token
+	if l.src != nil {
+		tok = Token{Type: TokenNumber, Value: l.text(start, l.pos)}
+	} else {
+		tok = Token{Type: TokenNumber, Value: string(strInt)}
+	}
+	if !validNumber(tok.Value) {
+		return Token{}, &SyntaxError{Msg: "invalid number " + strconv.Quote(tok.Value), Offset: start}
 	}
-	return Token{Type: TokenNumber, Value: string(strInt)}, nil
+	return tok, nil
 }
+
+// validNumber reports whether s follows the number grammar of RFC 8259:
+// an optional minus, an integer without leading zeros, then optional
+// fraction and exponent parts with at least one digit each.
+func validNumber(s string) bool {
+	i := 0
+	digits := func() int {
+		n := 0
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+			n++
+		}
+		return n
+	}
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch n := digits(); {
+	case n == 0:
+		return false
+	case n > 1 && s[i-n] == '0':
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// isNumberChar reports whether char can appear in a number, including the
+// fraction and exponent parts.
+func isNumberChar(char byte) bool {
+	switch char {
+	case '.', '-', '+', 'e', 'E':
+		return true
+	}
+	return char >= '0' && char <= '9'
+}
+
 func (l *Lexer) lexBoolean() (Token, error) {
 	for {
 		char, err := l.next()
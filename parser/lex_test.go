@@ -118,6 +118,8 @@ func TestLexNumberVariants(t *testing.T) {
 		{"negative", "-7", "-7"},
 		{"float", "3.14", "3.14"},
 		{"small float", "0.001", "0.001"},
+		{"exponent", "1e3", "1e3"},
+		{"signed exponent", "-2.5E-4", "-2.5E-4"},
 	}
 
 	for _, tc := range cases {
@@ -139,19 +141,27 @@ func TestLexNumberVariants(t *testing.T) {
 
 func TestLexStringEdgeCases(t *testing.T) {
 	cases := []struct {
-		name  string
-		input string
-		want  string
+		name    string
+		input   string
+		want    string
+		wantErr bool
 	}{
-		{"empty string", `""`, ""},
-		{"hello world", `"hello world"`, "hello world"},
-		{"unterminated", `"incomplete`, "incomplete"},
+		{"empty string", `""`, "", false},
+		{"hello world", `"hello world"`, "hello world", false},
+		{"unterminated", `"incomplete`, "", true},
+		{"raw newline", "\"a\nb\"", "", true},
 	}
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			l := NewLexer(strings.NewReader(tc.input))
 			tok, err := l.NextToken()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", tok)
+				}
+				return
+			}
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -326,3 +336,102 @@ func TestGenTokens_Nested(t *testing.T) {
 		})
 	}
 }
+
+func TestLexStringEscapes(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"quote", `"say \"hi\""`, `say "hi"`},
+		{"backslash", `"a\\b"`, `a\b`},
+		{"solidus", `"a\/b"`, "a/b"},
+		{"control", `"line\nnext\ttab\r\b\f"`, "line\nnext\ttab\r\b\f"},
+		{"unicode", `"caf\u00e9"`, "café"},
+		{"surrogate pair", `"\ud83d\ude00"`, "😀"},
+		{"lone surrogate", `"\ud83dx"`, "�x"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewLexer(strings.NewReader(tc.input))
+			tok, err := l.NextToken()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tok.Value != tc.want {
+				t.Fatalf("string mismatch: want %q got %q", tc.want, tok.Value)
+			}
+		})
+	}
+}
+
+func TestLexStringInvalidEscape(t *testing.T) {
+	for _, input := range []string{`"\x"`, `"\u12G4"`, `"\u12`} {
+		l := NewLexer(strings.NewReader(input))
+		if _, err := l.NextToken(); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
+
+func TestLexPositions(t *testing.T) {
+	// ë is two bytes and one UTF-16 unit, 😀 four bytes and two units
+	input := "{\"ë😀\": 12,\n\t\"b\":\r\n[true]}"
+	l := NewLexer(strings.NewReader(input))
+	expected := []struct {
+		value      string
+		start, end Position
+	}{
+		{"{", Position{0, 1, 1, 1}, Position{1, 1, 2, 2}},
+		{"ë😀", Position{1, 1, 2, 2}, Position{9, 1, 6, 7}},
+		{":", Position{9, 1, 6, 7}, Position{10, 1, 7, 8}},
+		// the number lexer reads the comma and unreads it
+		{"12", Position{11, 1, 8, 9}, Position{13, 1, 10, 11}},
+		{",", Position{13, 1, 10, 11}, Position{14, 1, 11, 12}},
+		{"b", Position{16, 2, 2, 2}, Position{19, 2, 5, 5}},
+		{":", Position{19, 2, 5, 5}, Position{20, 2, 6, 6}},
+		{"[", Position{22, 3, 1, 1}, Position{23, 3, 2, 2}},
+		{"true", Position{23, 3, 2, 2}, Position{27, 3, 6, 6}},
+		{"]", Position{27, 3, 6, 6}, Position{28, 3, 7, 7}},
+		{"}", Position{28, 3, 7, 7}, Position{29, 3, 8, 8}},
+		{"", Position{29, 3, 8, 8}, Position{29, 3, 8, 8}},
+	}
+	for i, exp := range expected {
+		tok, err := l.NextToken()
+		if err != nil {
+			t.Fatalf("token %d: unexpected error: %v", i, err)
+		}
+		if tok.Value != exp.value || tok.Start != exp.start || tok.End != exp.end {
+			t.Errorf("token %d: want %q %+v-%+v got %q %+v-%+v", i, exp.value, exp.start, exp.end, tok.Value, tok.Start, tok.End)
+		}
+	}
+}
+
+func TestLexPositionsMatchSourceMap(t *testing.T) {
+	input := "[\n  \"héllo\", 1.5e3,\n  {\"k\": null} // done\n]"
+	l := NewLexerDialect(strings.NewReader(input), JSONC)
+	m := &SourceMap{src: []byte(input)}
+	for {
+		tok, err := l.NextToken()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := m.Position(tok.Start.Offset); got != tok.Start {
+			t.Errorf("%q: lexer start %+v, source map %+v", tok.Value, tok.Start, got)
+		}
+		if got := m.Position(tok.End.Offset); got != tok.End {
+			t.Errorf("%q: lexer end %+v, source map %+v", tok.Value, tok.End, got)
+		}
+		if tok.Type == TokenEOF {
+			break
+		}
+	}
+}
+
+func TestUnexpectedTokenPosition(t *testing.T) {
+	_, err := ParseDialect(strings.NewReader("{\n  \"a\" 1}"), JSON)
+	if err == nil || err.Error() != "unexpected number, expected ':' at line 2, column 7" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package parser
+
+// MergePatch applies an RFC 7396 merge patch to target and returns the
+// result. A null member in the patch deletes that member from the target;
+// any patch that is not an object replaces the target outright. target is
+// not modified.
+func MergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return DeepCopy(patch)
+	}
+	t, ok := target.(map[string]any)
+	if ok {
+		t = DeepCopy(t).(map[string]any)
+	} else {
+		t = make(map[string]any, len(p))
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = MergePatch(t[k], v)
+	}
+	return t
+}
+
+// CreateMergePatch returns the merge patch that turns original into
+// modified. Removed members become null; arrays and scalars that differ are
+// replaced wholesale, since merge patches cannot address array elements.
+// A member that modified sets to null cannot be expressed and is dropped
+// from the result of applying the patch instead.
+func CreateMergePatch(original, modified any) any {
+	o, ok1 := original.(map[string]any)
+	m, ok2 := modified.(map[string]any)
+	if !ok1 || !ok2 {
+		return DeepCopy(modified)
+	}
+	patch := make(map[string]any)
+	for k := range o {
+		if _, ok := m[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	for k, mv := range m {
+		ov, ok := o[k]
+		if !ok {
+			patch[k] = DeepCopy(mv)
+			continue
+		}
+		if Equal(ov, mv) {
+			continue
+		}
+		_, ovObj := ov.(map[string]any)
+		_, mvObj := mv.(map[string]any)
+		if ovObj && mvObj {
+			patch[k] = CreateMergePatch(ov, mv)
+		} else {
+			patch[k] = DeepCopy(mv)
+		}
+	}
+	return patch
+}
@@ -0,0 +1,71 @@
+package parser
+
+import "testing"
+
+// test cases from RFC 7396 appendix A
+func TestMergePatchRFCExamples(t *testing.T) {
+	tests := []struct {
+		target   string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+			target := runParser(tt.target)
+			before := DeepCopy(target)
+			got := MergePatch(target, runParser(tt.patch))
+			if want := runParser(tt.expected); !Equal(got, want) {
+				t.Errorf("mismatch:\nexpected %#v\ngot      %#v", want, got)
+			}
+			if !Equal(target, before) {
+				t.Errorf("target was modified: %#v", target)
+			}
+		})
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		modified string
+		expected string
+	}{
+		{"no change", `{"a":1,"b":[1,2]}`, `{"a":1,"b":[1,2]}`, `{}`},
+		{"member removed", `{"a":1,"b":2}`, `{"a":1}`, `{"b":null}`},
+		{"member added", `{"a":1}`, `{"a":1,"b":{"c":true}}`, `{"b":{"c":true}}`},
+		{"nested change", `{"company":{"name":"x","bs":"y"}}`, `{"company":{"name":"z","bs":"y"}}`, `{"company":{"name":"z"}}`},
+		{"array replaced", `{"tags":[1,2]}`, `{"tags":[1,3]}`, `{"tags":[1,3]}`},
+		{"non-object", `{"a":1}`, `[1]`, `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, modified := runParser(tt.original), runParser(tt.modified)
+			patch := CreateMergePatch(original, modified)
+			if want := runParser(tt.expected); !Equal(patch, want) {
+				t.Errorf("patch mismatch:\nexpected %#v\ngot      %#v", want, patch)
+			}
+			if got := MergePatch(original, patch); !Equal(got, modified) {
+				t.Errorf("round trip mismatch:\nexpected %#v\ngot      %#v", modified, got)
+			}
+		})
+	}
+}
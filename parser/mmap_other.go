@@ -0,0 +1,14 @@
+//go:build !linux
+
+package parser
+
+import "os"
+
+// mapFile reads path into memory where mapping is not implemented.
+func mapFile(path string) (data []byte, release func() error, err error) {
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,374 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ParallelOptions configure ParseArrayParallel and StreamArray.
+type ParallelOptions struct {
+	// Workers is the number of goroutines parsing chunks; zero means one
+	// per CPU.
+	Workers int
+	// ChunkSize is the number of input bytes after which a chunk is cut at
+	// the next element boundary; zero means 1 MiB.
+	ChunkSize int
+}
+
+const defaultChunkSize = 1 << 20
+
+// ErrNotArray is returned by StreamArray for documents whose top-level
+// value is not an array.
+var ErrNotArray = errors.New("top-level value is not an array")
+
+// ArrayElement is one element of the top-level array delivered by
+// StreamArray.
+type ArrayElement struct {
+	Index int // position in the array
+	Value any
+	// Err is set, and Index and Value are not, on the last element
+	// StreamArrayStrict delivers when a chunk fails to parse.
+	Err error
+}
+
+// ChunkError reports a chunk of the top-level array that failed to parse in
+// ParseArrayParallelStrict or StreamArrayStrict.
+type ChunkError struct {
+	Offset int   // of the first byte of the chunk
+	Index  int   // of the first element of the chunk in the array
+	Err    error // with offsets and positions in the whole input
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("array chunk at offset %d (element %d): %v", e.Offset, e.Index, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error { return e.Err }
+
+// arrayChunk is a run of whole elements of the top-level array: the bytes
+// between two separating commas, or between a bracket and a comma.
+type arrayChunk struct {
+	start, end int
+	index      int // index of the first element in the array
+}
+
+// splitArray cuts the top-level array of data into chunks of at least size
+// bytes. It only follows brackets and strings, so it is much cheaper than
+// lexing; ok is false when data does not start with an array.
+func splitArray(data []byte, size int) (chunks []arrayChunk, ok bool) {
+	i := 0
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	if i == len(data) || data[i] != '[' {
+		return nil, false
+	}
+	i++
+	cur := arrayChunk{start: i}
+	count := 0       // elements of cur seen so far
+	element := false // whether the current element has started
+	depth := 0
+	for ; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '"':
+			i = skipString(data, i)
+			element = true
+		case '[', '{':
+			depth++
+			element = true
+		case ']', '}':
+			if depth > 0 {
+				depth--
+			} else if c == ']' {
+				cur.end = i
+				return append(chunks, cur), true
+			}
+		case ',':
+			if depth > 0 {
+				continue
+			}
+			if element {
+				count++
+				element = false
+			}
+			if i-cur.start >= size {
+				cur.end = i
+				chunks = append(chunks, cur)
+				cur = arrayChunk{start: i + 1, index: cur.index + count}
+				count = 0
+			}
+		case ' ', '\t', '\n', '\r':
+		default:
+			element = true
+		}
+	}
+	// unterminated array: the last chunk runs to the end of the input
+	cur.end = len(data)
+	return append(chunks, cur), true
+}
+
+// skipString returns the index of the quote closing the string that starts
+// at data[i], or len(data) if it is unterminated.
+func skipString(data []byte, i int) int {
+	for i++; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(data)
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// parse parses the elements of a chunk the way BasicParase parses the
+// whole array.
+func (c arrayChunk) parse(data []byte) []any {
+	r := io.MultiReader(strings.NewReader("["), bytes.NewReader(data[c.start:c.end]), strings.NewReader("]"))
+	items, _ := BasicParase(r).([]any)
+	return items
+}
+
+// parseStrict parses the elements of c the way ParseDialect parses the
+// whole array, lexing them in place so that errors carry their offset in
+// data. at is the position of c.start; lines and columns are only right if
+// it is. An empty chunk is an error when the array has others, as it stands
+// for an element missing between two commas.
+func (c arrayChunk) parseStrict(data []byte, at Position, alone bool) ([]any, error) {
+	l := getBytesLexer(data[:c.end], JSON)
+	defer putLexer(l)
+	l.pos = c.start
+	l.line, l.col, l.col16 = at.Line-1, at.Column-1, at.UTF16Column-1
+	l.depth = 1 // inside the top-level array
+	tok, err := l.NextToken()
+	if err != nil {
+		return nil, err
+	}
+	if tok.Type == TokenEOF {
+		if alone {
+			return []any{}, nil
+		}
+		return nil, &SyntaxError{Msg: "missing array element", Offset: c.end}
+	}
+	var items []any
+	for {
+		v, err := DecodeAny(l, tok)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+		if tok, err = l.NextToken(); err != nil {
+			return nil, err
+		}
+		switch tok.Type {
+		case TokenEOF:
+			return items, nil
+		case TokenComma:
+		default:
+			return nil, &UnexpectedTokenError{Got: tok, Want: "',' or ']'"}
+		}
+		if tok, err = l.NextToken(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// chunkError parses the failed chunk c again from its actual position, so
+// that the error reports the right line and column; counting lines up to
+// every chunk would cost a pass over the input.
+func chunkError(data []byte, c arrayChunk, alone bool) error {
+	l := NewLexerBytes(data[:c.start], JSON)
+	for {
+		if _, err := l.next(); err != nil {
+			break
+		}
+	}
+	_, err := c.parseStrict(data, l.position(), alone)
+	return &ChunkError{Offset: c.start, Index: c.index, Err: err}
+}
+
+// checkArray reports what splitArray does not look at: an array that is
+// never closed and data following it.
+func checkArray(data []byte, chunks []arrayChunk) error {
+	last := chunks[len(chunks)-1]
+	if last.end == len(data) {
+		return &SyntaxError{Msg: "unterminated array", Offset: chunks[0].start - 1}
+	}
+	for i := last.end + 1; i < len(data); i++ {
+		if !isSpace(data[i]) {
+			return &SyntaxError{Msg: "data after the top-level array", Offset: i}
+		}
+	}
+	return nil
+}
+
+// parseChunks runs fn for every chunk index on a bounded number of
+// goroutines and returns when all are done.
+func parseChunks(count, workers int, fn func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(min(workers, count), 1)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range count {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// ParseArrayParallel parses data like BasicParase, but when the top-level
+// value is an array it is split into chunks at element boundaries which are
+// parsed concurrently and reassembled in order. Other documents are parsed
+// sequentially.
+//
+// Like BasicParase it does not report syntax errors, and on malformed input
+// every chunk recovers on its own, so the result can differ from parsing
+// sequentially. Use ParseArrayParallelStrict to reject such input.
+func ParseArrayParallel(data []byte, opts ParallelOptions) any {
+	chunks, ok := splitArray(data, chunkSize(opts))
+	if !ok {
+		return BasicParase(bytes.NewReader(data))
+	}
+	parts := make([][]any, len(chunks))
+	parseChunks(len(chunks), opts.Workers, func(i int) {
+		parts[i] = chunks[i].parse(data)
+	})
+	if out := concat(parts); len(out) > 0 {
+		return out
+	}
+	// BasicParase returns a nil slice for an empty array
+	return []any(nil)
+}
+
+// ParseArrayParallelStrict is ParseArrayParallel with the strictness of
+// ParseDialect for JSON: it returns the same value or fails on the same
+// documents. When a chunk of the array fails to parse, the error is a
+// *ChunkError for the first such chunk.
+func ParseArrayParallelStrict(data []byte, opts ParallelOptions) (any, error) {
+	chunks, ok := splitArray(data, chunkSize(opts))
+	if !ok {
+		l := getBytesLexer(data, JSON)
+		defer putLexer(l)
+		return parseDocument(l)
+	}
+	if err := checkArray(data, chunks); err != nil {
+		return nil, err
+	}
+	parts := make([][]any, len(chunks))
+	failed := make([]bool, len(chunks))
+	alone := len(chunks) == 1
+	parseChunks(len(chunks), opts.Workers, func(i int) {
+		var err error
+		parts[i], err = chunks[i].parseStrict(data, Position{Line: 1, Column: 1, UTF16Column: 1}, alone)
+		failed[i] = err != nil
+	})
+	for i, f := range failed {
+		if f {
+			return nil, chunkError(data, chunks[i], alone)
+		}
+	}
+	return concat(parts), nil
+}
+
+func concat(parts [][]any) []any {
+	n := 0
+	for _, part := range parts {
+		n += len(part)
+	}
+	out := make([]any, 0, n)
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
+
+// StreamArray parses the top-level array of data on several goroutines and
+// delivers its elements as soon as their chunk is parsed. Elements of one
+// chunk arrive in order, chunks in any order; Index gives the position in
+// the array when data is valid JSON. The channel is closed after the last
+// element and must be drained.
+//
+// Like ParseArrayParallel it does not report syntax errors; use
+// StreamArrayStrict to have them.
+func StreamArray(data []byte, opts ParallelOptions) (<-chan ArrayElement, error) {
+	chunks, ok := splitArray(data, chunkSize(opts))
+	if !ok {
+		return nil, ErrNotArray
+	}
+	out := make(chan ArrayElement, 64)
+	go func() {
+		defer close(out)
+		parseChunks(len(chunks), opts.Workers, func(i int) {
+			for j, item := range chunks[i].parse(data) {
+				out <- ArrayElement{Index: chunks[i].index + j, Value: item}
+			}
+		})
+	}()
+	return out, nil
+}
+
+// StreamArrayStrict is StreamArray with the strictness of
+// ParseDialect for JSON. An unterminated array and data after it are
+// reported before anything is delivered. A chunk is delivered only once it
+// parsed in full; when one fails, no further chunk is delivered, and the
+// last element carries a *ChunkError in Err.
+func StreamArrayStrict(data []byte, opts ParallelOptions) (<-chan ArrayElement, error) {
+	chunks, ok := splitArray(data, chunkSize(opts))
+	if !ok {
+		return nil, ErrNotArray
+	}
+	if err := checkArray(data, chunks); err != nil {
+		return nil, err
+	}
+	alone := len(chunks) == 1
+	out := make(chan ArrayElement, 64)
+	go func() {
+		defer close(out)
+		var mu sync.Mutex
+		failed := false
+		parseChunks(len(chunks), opts.Workers, func(i int) {
+			items, err := chunks[i].parseStrict(data, Position{Line: 1, Column: 1, UTF16Column: 1}, alone)
+			mu.Lock()
+			defer mu.Unlock()
+			// delivering under the lock keeps chunks after a failure out
+			switch {
+			case failed:
+			case err != nil:
+				failed = true
+				out <- ArrayElement{Err: chunkError(data, chunks[i], alone)}
+			default:
+				for j, item := range items {
+					out <- ArrayElement{Index: chunks[i].index + j, Value: item}
+				}
+			}
+		})
+	}()
+	return out, nil
+}
+
+func chunkSize(opts ParallelOptions) int {
+	if opts.ChunkSize <= 0 {
+		return defaultChunkSize
+	}
+	return opts.ChunkSize
+}
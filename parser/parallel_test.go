@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseArrayParallelMatchesBasicParase(t *testing.T) {
+	files, err := filepath.Glob("../test_data/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := BasicParase(bytes.NewReader(data))
+		for _, opts := range []ParallelOptions{{}, {Workers: 1, ChunkSize: 1}, {Workers: 4, ChunkSize: 100}, {Workers: 3, ChunkSize: 4096}} {
+			if got := ParseArrayParallel(data, opts); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s with %+v: result differs from BasicParase", file, opts)
+			}
+		}
+	}
+}
+
+func TestParseArrayParallelEdgeCases(t *testing.T) {
+	inputs := []string{
+		`[]`,
+		`  [ ]  `,
+		`[1]`,
+		`[1, "a,b", "q\"],[", {"x": [1, {"y": "]"}]}, [[], {}], true, null, -2.5e3]`,
+		`["\\", "\\\"", ","]`,
+		"[\n\t1 ,\n\t2\n]",
+		`[1, 2,]`,
+		`[1, 2`,
+		`{"a": [1, 2]}`,
+		`"text"`,
+		``,
+	}
+	for _, in := range inputs {
+		want := runParser(in)
+		for _, size := range []int{1, 2, 3, 1000} {
+			got := ParseArrayParallel([]byte(in), ParallelOptions{Workers: 2, ChunkSize: size})
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q with chunk size %d: got %#v, want %#v", in, size, got, want)
+			}
+		}
+	}
+}
+
+func TestSplitArray(t *testing.T) {
+	data := []byte(`[1, "a,b", [2, 3], {"c": ","}, 4]`)
+	chunks, ok := splitArray(data, 1)
+	if !ok {
+		t.Fatalf("not an array")
+	}
+	var got []string
+	var index []int
+	for _, c := range chunks {
+		got = append(got, string(data[c.start:c.end]))
+		index = append(index, c.index)
+	}
+	want := []string{`1`, ` "a,b"`, ` [2, 3]`, ` {"c": ","}`, ` 4`}
+	if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(index, []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("chunks %q at %v, want %q", got, index, want)
+	}
+	if _, ok := splitArray([]byte(` {"a": 1}`), 1); ok {
+		t.Fatalf("object split as an array")
+	}
+}
+
+func TestStreamArray(t *testing.T) {
+	data, err := os.ReadFile("../test_data/example_todos.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BasicParase(bytes.NewReader(data)).([]any)
+	out, err := StreamArray(data, ParallelOptions{Workers: 4, ChunkSize: 512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []ArrayElement
+	for e := range out {
+		got = append(got, e)
+	}
+	sort.Slice(got, func(i, j int) bool { return got[i].Index < got[j].Index })
+	if len(got) != len(want) {
+		t.Fatalf("got %d elements, want %d", len(got), len(want))
+	}
+	for i, e := range got {
+		if e.Index != i || !reflect.DeepEqual(e.Value, want[i]) {
+			t.Fatalf("element %d: got %v at index %d, want %v", i, e.Value, e.Index, want[i])
+		}
+	}
+	if _, err := StreamArray([]byte(`{}`), ParallelOptions{}); err != ErrNotArray {
+		t.Fatalf("got error %v, want ErrNotArray", err)
+	}
+}
+
+func BenchmarkParseArrayParallel(b *testing.B) {
+	data, err := os.ReadFile("../test_data/example_todos.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	// a large array made of many copies of the fixture's elements
+	big := append([]byte{'['}, bytes.Repeat(append(bytes.Trim(bytes.TrimSpace(data), "[]"), ','), 200)...)
+	big = append(big[:len(big)-1], ']')
+	b.Run("BasicParase", func(b *testing.B) {
+		b.SetBytes(int64(len(big)))
+		for i := 0; i < b.N; i++ {
+			BasicParase(bytes.NewReader(big))
+		}
+	})
+	b.Run("Parallel", func(b *testing.B) {
+		b.SetBytes(int64(len(big)))
+		for i := 0; i < b.N; i++ {
+			ParseArrayParallel(big, ParallelOptions{ChunkSize: 64 << 10})
+		}
+	})
+}
+
+func TestParseArrayParallelStrict(t *testing.T) {
+	inputs := []string{
+		`[]`,
+		`  [ ]  `,
+		`[1]`,
+		`[1, "a,b", "q\"],[", {"x": [1, {"y": "]"}]}, [[], {}], true, null, -2.5e3]`,
+		"[\n\t1 ,\n\t2\n]",
+		`[1, 2, x, 3, 4]`,
+		`[1, 2,]`,
+		`[1,, 2]`,
+		`[, 1]`,
+		`[1 2]`,
+		`[1, 2`,
+		`[1, 2] 3`,
+		`[1, "a`,
+		`[1, [2}, 3]`,
+		`[01, 2]`,
+		`{"a": [1, 2]}`,
+		`"text"`,
+		``,
+	}
+	for _, in := range inputs {
+		want, wantErr := ParseDialect(bytes.NewReader([]byte(in)), JSON)
+		for _, size := range []int{1, 2, 3, 1000} {
+			got, err := ParseArrayParallelStrict([]byte(in), ParallelOptions{Workers: 2, ChunkSize: size})
+			if (err == nil) != (wantErr == nil) || !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q with chunk size %d: got %#v, %v, want %#v, %v", in, size, got, err, want, wantErr)
+			}
+		}
+	}
+}
+
+func TestParseArrayParallelStrictError(t *testing.T) {
+	data := []byte("[\n  1,\n  2,\n  [3},\n  4\n]")
+	_, err := ParseArrayParallelStrict(data, ParallelOptions{Workers: 2, ChunkSize: 1})
+	var cerr *ChunkError
+	if !errors.As(err, &cerr) || cerr.Index != 2 || cerr.Offset != 11 {
+		t.Fatalf("got %v, want a ChunkError for element 2 at offset 11", err)
+	}
+	var terr *UnexpectedTokenError
+	if !errors.As(err, &terr) || terr.Got.Start.Offset != 16 || terr.Got.Start.Line != 4 || terr.Got.Start.Column != 5 {
+		t.Fatalf("got %v, want the error at offset 16, line 4, column 5", err)
+	}
+}
+
+func TestStreamArrayStrict(t *testing.T) {
+	collect := func(data string) ([]any, error) {
+		out, err := StreamArrayStrict([]byte(data), ParallelOptions{Workers: 2, ChunkSize: 1})
+		if err != nil {
+			return nil, err
+		}
+		var got []ArrayElement
+		for e := range out {
+			if e.Err != nil {
+				err = e.Err
+			} else {
+				got = append(got, e)
+			}
+		}
+		sort.Slice(got, func(i, j int) bool { return got[i].Index < got[j].Index })
+		values := []any{}
+		for _, e := range got {
+			values = append(values, e.Value)
+		}
+		return values, err
+	}
+	if got, err := collect(`[1, {"a": [2]}, "x"]`); err != nil || !reflect.DeepEqual(got, []any{1.0, map[string]any{"a": []any{2.0}}, "x"}) {
+		t.Fatalf("got %#v, %v", got, err)
+	}
+	var cerr *ChunkError
+	if _, err := collect(`[1, 2, x, 3]`); !errors.As(err, &cerr) || cerr.Index != 2 {
+		t.Fatalf("got %v, want a ChunkError for element 2", err)
+	}
+	var serr *SyntaxError
+	for _, in := range []string{`[1, 2`, `[1] x`} {
+		if _, err := collect(in); !errors.As(err, &serr) {
+			t.Fatalf("%q: got %v, want a SyntaxError", in, err)
+		}
+	}
+	if _, err := StreamArrayStrict([]byte(`{}`), ParallelOptions{}); err != ErrNotArray {
+		t.Fatalf("got error %v, want ErrNotArray", err)
+	}
+}
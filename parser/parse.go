@@ -1,9 +1,7 @@
 package parser
 
 import (
-	"fmt"
 	"io"
-	"strconv"
 )
 
 // for now assume that the json is valid and we dont have to check for errors
@@ -69,8 +67,6 @@ func (p *Parser) parse_array() []any {
 		case TokenComma:
 			continue
 		case TokenString, TokenNumber, TokenTrue, TokenFalse, TokenNull:
-			trueVal := parseLiteral(tok)
-			fmt.Printf("Parser.parse_array(): passed in value %T parsed value %T\n", tok.Value, trueVal)
 			arr = append(arr, parseLiteral(tok))
 		case TokenLeftBrace:
 			arr = append(arr, p.parse_object())
@@ -90,7 +86,7 @@ func parseLiteral(tok Token) any {
 	case TokenNull:
 		return nil
 	case TokenNumber:
-		if i, err := strconv.ParseFloat(tok.Value, 64); err == nil {
+		if i, err := parseNumber(tok.Value); err == nil {
 			return i
 		}
 	}
@@ -120,9 +116,7 @@ func (p *Parser) consume() map[string]any {
 
 			switch value.Type {
 			case TokenString, TokenNumber, TokenTrue, TokenFalse, TokenNull:
-				trueVal := parseLiteral(value)
-				fmt.Printf("Parser.Consume(): passed in value %T parsed value %T\n", value.Value, trueVal)
-				obj[key] = trueVal
+				obj[key] = parseLiteral(value)
 			case TokenLeftBrace:
 				obj[key] = p.parse_object()
 			case TokenLeftBracket:
@@ -0,0 +1,399 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Operation is a single RFC 6902 JSON Patch operation.
+type Operation struct {
+	Op    string // add, remove, replace, move, copy or test
+	Path  string
+	From  string // only used by move and copy
+	Value any    // only used by add, replace and test
+}
+
+// PatchError reports which operation of a patch document failed and why.
+type PatchError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("patch operation %d (%s %s): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error { return e.Err }
+
+// ErrTestFailed is wrapped by the PatchError returned when a test operation
+// does not match the document.
+var ErrTestFailed = errors.New("test operation failed")
+
+// ParsePatch reads a patch document and decodes it into operations. A
+// document that is not valid JSON is rejected as a whole.
+func ParsePatch(r io.Reader) ([]Operation, error) {
+	v, err := ParseDialect(r, JSON)
+	if err != nil {
+		return nil, fmt.Errorf("patch document: %w", err)
+	}
+	return DecodePatch(v)
+}
+
+// DecodePatch converts a parsed patch document (an array of objects) into
+// operations, checking that every operation has the members it needs.
+func DecodePatch(v any) ([]Operation, error) {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("patch document must be an array, got %s", kindOf(v))
+	}
+	ops := make([]Operation, 0, len(arr))
+	for i, item := range arr {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, &PatchError{Index: i, Err: fmt.Errorf("operation must be an object, got %s", kindOf(item))}
+		}
+		var op Operation
+		var err error
+		if op.Op, err = stringMember(obj, "op"); err != nil {
+			return nil, &PatchError{Index: i, Err: err}
+		}
+		if op.Path, err = stringMember(obj, "path"); err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Err: err}
+		}
+		switch op.Op {
+		case "add", "replace", "test":
+			val, ok := obj["value"]
+			if !ok {
+				return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: errors.New(`missing "value" member`)}
+			}
+			op.Value = val
+		case "move", "copy":
+			if op.From, err = stringMember(obj, "from"); err != nil {
+				return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+			}
+		case "remove":
+		default:
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: fmt.Errorf("unknown operation %q", op.Op)}
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+func stringMember(obj map[string]any, name string) (string, error) {
+	raw, ok := obj[name]
+	if !ok {
+		return "", fmt.Errorf("missing %q member", name)
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%q member must be a string, got %s", name, kindOf(raw))
+	}
+	return s, nil
+}
+
+// PatchValue converts operations back into the parser's value representation
+// so they can be encoded as a patch document.
+func PatchValue(ops []Operation) []any {
+	out := make([]any, 0, len(ops))
+	for _, op := range ops {
+		obj := map[string]any{"op": op.Op, "path": op.Path}
+		switch op.Op {
+		case "add", "replace", "test":
+			obj["value"] = op.Value
+		case "move", "copy":
+			obj["from"] = op.From
+		}
+		out = append(out, obj)
+	}
+	return out
+}
+
+// ApplyPatch applies ops to doc atomically: the operations run against a deep
+// copy, so if any of them fails the returned error is a *PatchError and doc is
+// left untouched. The patched document is returned on success.
+func ApplyPatch(doc any, ops []Operation) (any, error) {
+	cur := DeepCopy(doc)
+	for i, op := range ops {
+		var err error
+		cur, err = applyOperation(cur, op)
+		if err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return cur, nil
+}
+
+func applyOperation(doc any, op Operation) (any, error) {
+	path, err := ParsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		return addValue(doc, path, DeepCopy(op.Value))
+	case "remove":
+		doc, _, err := removeValue(doc, path)
+		return doc, err
+	case "replace":
+		if _, err := path.Get(doc); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			return DeepCopy(op.Value), nil
+		}
+		doc, _, err := removeValue(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, DeepCopy(op.Value))
+	case "move":
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		if isProperPrefix(from, path) {
+			return nil, fmt.Errorf("cannot move %q into its own child", op.From)
+		}
+		doc, val, err := removeValue(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, val)
+	case "copy":
+		from, err := ParsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+		val, err := from.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		return addValue(doc, path, DeepCopy(val))
+	case "test":
+		val, err := path.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+		if !Equal(val, op.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+func isProperPrefix(prefix, p Pointer) bool {
+	if len(prefix) >= len(p) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != p[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// addValue implements the RFC 6902 "add" semantics and returns the new root.
+func addValue(doc any, path Pointer, val any) (any, error) {
+	if len(path) == 0 {
+		return val, nil
+	}
+	parentPath, tok := path.Parent()
+	parent, err := parentPath.Get(doc)
+	if err != nil {
+		return nil, err
+	}
+	switch node := parent.(type) {
+	case map[string]any:
+		node[tok] = val
+		return doc, nil
+	case []any:
+		idx := len(node)
+		if tok != "-" {
+			if idx, err = arrayIndex(tok, len(node)+1); err != nil {
+				return nil, err
+			}
+		}
+		grown := make([]any, 0, len(node)+1)
+		grown = append(grown, node[:idx]...)
+		grown = append(grown, val)
+		grown = append(grown, node[idx:]...)
+		return replaceAt(doc, parentPath, grown), nil
+	default:
+		return nil, fmt.Errorf("cannot add to %s", kindOf(parent))
+	}
+}
+
+// removeValue deletes the value at path and returns the new root together
+// with the removed value.
+func removeValue(doc any, path Pointer) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, errors.New("cannot remove the document root")
+	}
+	parentPath, tok := path.Parent()
+	parent, err := parentPath.Get(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch node := parent.(type) {
+	case map[string]any:
+		val, ok := node[tok]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q not found", tok)
+		}
+		delete(node, tok)
+		return doc, val, nil
+	case []any:
+		idx, err := arrayIndex(tok, len(node))
+		if err != nil {
+			return nil, nil, err
+		}
+		val := node[idx]
+		shrunk := make([]any, 0, len(node)-1)
+		shrunk = append(shrunk, node[:idx]...)
+		shrunk = append(shrunk, node[idx+1:]...)
+		return replaceAt(doc, parentPath, shrunk), val, nil
+	default:
+		return nil, nil, fmt.Errorf("cannot remove from %s", kindOf(parent))
+	}
+}
+
+// replaceAt stores val at an existing location. Slices change identity when
+// they grow or shrink, so the container that holds them must be updated too.
+func replaceAt(doc any, path Pointer, val any) any {
+	if len(path) == 0 {
+		return val
+	}
+	parentPath, tok := path.Parent()
+	parent, _ := parentPath.Get(doc)
+	switch node := parent.(type) {
+	case map[string]any:
+		node[tok] = val
+	case []any:
+		idx, _ := strconv.Atoi(tok)
+		node[idx] = val
+	}
+	return doc
+}
+
+// CreatePatch returns a patch that turns from into to. Objects are compared
+// member by member and arrays element by element, so unchanged subtrees never
+// appear in the output.
+func CreatePatch(from, to any) []Operation {
+	return diffValues(nil, Pointer{}, from, to)
+}
+
+func diffValues(ops []Operation, path Pointer, from, to any) []Operation {
+	switch a := from.(type) {
+	case map[string]any:
+		b, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		for _, k := range sortedKeys(a) {
+			if _, ok := b[k]; !ok {
+				ops = append(ops, Operation{Op: "remove", Path: path.Append(k).String()})
+			}
+		}
+		for _, k := range sortedKeys(b) {
+			if av, ok := a[k]; ok {
+				ops = diffValues(ops, path.Append(k), av, b[k])
+			} else {
+				ops = append(ops, Operation{Op: "add", Path: path.Append(k).String(), Value: DeepCopy(b[k])})
+			}
+		}
+		return ops
+	case []any:
+		b, ok := to.([]any)
+		if !ok {
+			break
+		}
+		n := min(len(a), len(b))
+		for i := 0; i < n; i++ {
+			ops = diffValues(ops, path.Append(strconv.Itoa(i)), a[i], b[i])
+		}
+		// remove from the back so earlier indexes stay valid
+		for i := len(a) - 1; i >= n; i-- {
+			ops = append(ops, Operation{Op: "remove", Path: path.Append(strconv.Itoa(i)).String()})
+		}
+		for i := n; i < len(b); i++ {
+			ops = append(ops, Operation{Op: "add", Path: path.Append(strconv.Itoa(i)).String(), Value: DeepCopy(b[i])})
+		}
+		return ops
+	}
+	if !Equal(from, to) {
+		ops = append(ops, Operation{Op: "replace", Path: path.String(), Value: DeepCopy(to)})
+	}
+	return ops
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DeepCopy returns a copy of a parsed value that shares no maps or slices
+// with the original.
+func DeepCopy(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(node))
+		for k, child := range node {
+			out[k] = DeepCopy(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(node))
+		for i, child := range node {
+			out[i] = DeepCopy(child)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// Equal reports whether two parsed values are the same JSON value. Unlike
+// reflect.DeepEqual it treats a nil []any (what the parser yields for "[]")
+// and an empty one as equal.
+func Equal(a, b any) bool {
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !Equal(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !Equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustPatch(t *testing.T, input string) []Operation {
+	t.Helper()
+	ops, err := ParsePatch(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error decoding patch: %v", err)
+	}
+	return ops
+}
+
+// examples from RFC 6902 appendix A
+func TestApplyPatchRFCExamples(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "A.1 add object member",
+			doc:      `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/baz","value":"qux"}]`,
+			expected: `{"baz":"qux","foo":"bar"}`,
+		},
+		{
+			name:     "A.2 add array element",
+			doc:      `{"foo":["bar","baz"]}`,
+			patch:    `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+			expected: `{"foo":["bar","qux","baz"]}`,
+		},
+		{
+			name:     "A.3 remove object member",
+			doc:      `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"remove","path":"/baz"}]`,
+			expected: `{"foo":"bar"}`,
+		},
+		{
+			name:     "A.4 remove array element",
+			doc:      `{"foo":["bar","qux","baz"]}`,
+			patch:    `[{"op":"remove","path":"/foo/1"}]`,
+			expected: `{"foo":["bar","baz"]}`,
+		},
+		{
+			name:     "A.5 replace value",
+			doc:      `{"baz":"qux","foo":"bar"}`,
+			patch:    `[{"op":"replace","path":"/baz","value":"boo"}]`,
+			expected: `{"baz":"boo","foo":"bar"}`,
+		},
+		{
+			name:     "A.6 move value",
+			doc:      `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			patch:    `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			expected: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+		},
+		{
+			name:     "A.7 move array element",
+			doc:      `{"foo":["all","grass","cows","eat"]}`,
+			patch:    `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			expected: `{"foo":["all","cows","eat","grass"]}`,
+		},
+		{
+			name:     "A.10 add nested member object",
+			doc:      `{"foo":"bar"}`,
+			patch:    `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`,
+			expected: `{"foo":"bar","child":{"grandchild":{}}}`,
+		},
+		{
+			name:     "A.14 tilde escape ordering",
+			doc:      `{"/":9,"~1":10}`,
+			patch:    `[{"op":"test","path":"/~01","value":10}]`,
+			expected: `{"/":9,"~1":10}`,
+		},
+		{
+			name:     "A.16 add array value",
+			doc:      `{"foo":["bar"]}`,
+			patch:    `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`,
+			expected: `{"foo":["bar",["abc","def"]]}`,
+		},
+		{
+			name:     "copy",
+			doc:      `{"a":{"b":1}}`,
+			patch:    `[{"op":"copy","from":"/a","path":"/c"}]`,
+			expected: `{"a":{"b":1},"c":{"b":1}}`,
+		},
+		{
+			name:     "replace root",
+			doc:      `{"a":1}`,
+			patch:    `[{"op":"replace","path":"","value":[1,2]}]`,
+			expected: `[1,2]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyPatch(runParser(tt.doc), mustPatch(t, tt.patch))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := runParser(tt.expected); !Equal(got, want) {
+				t.Errorf("mismatch:\nexpected %#v\ngot      %#v", want, got)
+			}
+		})
+	}
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		index int
+	}{
+		{"A.8 test failure", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/baz","value":"bar"}]`, 1},
+		{"A.9 add to nonexistent target", `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, 0},
+		{"A.15 comparing strings and numbers", `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":"10"}]`, 0},
+		{"remove missing", `{"a":1}`, `[{"op":"remove","path":"/a"},{"op":"remove","path":"/a"}]`, 1},
+		{"move into child", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/b/c"}]`, 0},
+		{"index past end", `[1,2]`, `[{"op":"add","path":"/3","value":0}]`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := runParser(tt.doc)
+			before := DeepCopy(doc)
+			_, err := ApplyPatch(doc, mustPatch(t, tt.patch))
+			var perr *PatchError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected *PatchError, got %v", err)
+			}
+			if perr.Index != tt.index {
+				t.Errorf("expected failing index %d, got %d (%v)", tt.index, perr.Index, err)
+			}
+			if !Equal(doc, before) {
+				t.Errorf("document was modified by a failed patch: %#v", doc)
+			}
+		})
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	doc := runParser(`{"a":1}`)
+	ops := mustPatch(t, `[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":5}]`)
+	_, err := ApplyPatch(doc, ops)
+	if !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("expected ErrTestFailed, got %v", err)
+	}
+	if _, ok := doc.(map[string]any)["b"]; ok {
+		t.Fatalf("first operation leaked into the original document")
+	}
+}
+
+func TestDecodePatchErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"not an array", `{"op":"add"}`, "must be an array"},
+		{"unknown op", `[{"op":"frob","path":"/a"}]`, "unknown operation"},
+		{"missing value", `[{"op":"add","path":"/a"}]`, `missing "value"`},
+		{"missing from", `[{"op":"move","path":"/a"}]`, `missing "from"`},
+		{"missing comma", `[{"op":"replace","path":"/a","value":1} {"op":"remove","path":"/b"}]`, "patch document: unexpected"},
+		{"truncated", `[{"op":"remove","path":"/b"}`, "patch document"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePatch(strings.NewReader(tt.patch))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCreatePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  string
+		to    string
+		count int
+	}{
+		{"identical", `{"a":[1,2,{"b":true}]}`, `{"a":[1,2,{"b":true}]}`, 0},
+		{"replace scalar", `{"a":1,"b":2}`, `{"a":1,"b":3}`, 1},
+		{"add and remove members", `{"a":1,"b":2}`, `{"b":2,"c":3}`, 2},
+		{"nested change", `{"user":{"address":{"geo":{"lat":"1"}}}}`, `{"user":{"address":{"geo":{"lat":"2"}}}}`, 1},
+		{"array grows", `[1,2]`, `[1,2,3,4]`, 2},
+		{"array shrinks", `[1,2,3,4]`, `[1,2]`, 2},
+		{"type change", `{"a":[1]}`, `{"a":{"x":1}}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := runParser(tt.from), runParser(tt.to)
+			ops := CreatePatch(from, to)
+			if len(ops) != tt.count {
+				t.Errorf("expected %d operations, got %d: %+v", tt.count, len(ops), ops)
+			}
+			got, err := ApplyPatch(from, ops)
+			if err != nil {
+				t.Fatalf("generated patch failed to apply: %v", err)
+			}
+			if !Equal(got, to) {
+				t.Errorf("round trip mismatch:\nexpected %#v\ngot      %#v", to, got)
+			}
+			if _, err := DecodePatch(PatchValue(ops)); err != nil {
+				t.Errorf("generated patch does not decode: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer is a decoded RFC 6901 JSON Pointer. Each element is one reference
+// token with the ~0 / ~1 escapes already removed, so "/a~1b/0" becomes
+// Pointer{"a/b", "0"}. The empty Pointer refers to the whole document.
+type Pointer []string
+
+// ParsePointer decodes a JSON Pointer string such as "/address/geo/lat".
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("json pointer %q must start with '/'", s)
+	}
+	parts := strings.Split(s[1:], "/")
+	p := make(Pointer, len(parts))
+	for i, part := range parts {
+		tok, err := unescapePointerToken(part)
+		if err != nil {
+			return nil, fmt.Errorf("json pointer %q: %w", s, err)
+		}
+		p[i] = tok
+	}
+	return p, nil
+}
+
+func unescapePointerToken(s string) (string, error) {
+	if !strings.Contains(s, "~") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '~' {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			return "", fmt.Errorf("dangling '~' in token %q", s)
+		}
+		switch s[i+1] {
+		case '0':
+			b.WriteByte('~')
+		case '1':
+			b.WriteByte('/')
+		default:
+			return "", fmt.Errorf("invalid escape '~%c' in token %q", s[i+1], s)
+		}
+		i++
+	}
+	return b.String(), nil
+}
+
+// EscapePointerToken applies the ~0 / ~1 escapes to a single reference token.
+func EscapePointerToken(s string) string {
+	if !strings.ContainsAny(s, "~/") {
+		return s
+	}
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+// String encodes the pointer back into its RFC 6901 string form.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, tok := range p {
+		b.WriteByte('/')
+		b.WriteString(EscapePointerToken(tok))
+	}
+	return b.String()
+}
+
+// Append returns a new pointer with tok added as the last reference token.
+func (p Pointer) Append(tok string) Pointer {
+	out := make(Pointer, len(p), len(p)+1)
+	copy(out, p)
+	return append(out, tok)
+}
+
+// Parent splits the pointer into the pointer of the containing value and the
+// final reference token. It must not be called on the root pointer.
+func (p Pointer) Parent() (Pointer, string) {
+	return p[:len(p)-1], p[len(p)-1]
+}
+
+// Get resolves the pointer against a value produced by the parser.
+func (p Pointer) Get(doc any) (any, error) {
+	cur := doc
+	for i, tok := range p {
+		switch node := cur.(type) {
+		case map[string]any:
+			v, ok := node[tok]
+			if !ok {
+				return nil, fmt.Errorf("json pointer %q: member %q not found", p[:i+1].String(), tok)
+			}
+			cur = v
+		case []any:
+			idx, err := arrayIndex(tok, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("json pointer %q: %w", p[:i+1].String(), err)
+			}
+			cur = node[idx]
+		default:
+			return nil, fmt.Errorf("json pointer %q: cannot index into %s", p[:i+1].String(), kindOf(cur))
+		}
+	}
+	return cur, nil
+}
+
+// arrayIndex validates an array reference token against an array of length n.
+// Leading zeros and "-" are rejected; callers that accept "-" handle it first.
+func arrayIndex(tok string, n int) (int, error) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	for i := 0; i < len(tok); i++ {
+		if tok[i] < '0' || tok[i] > '9' {
+			return 0, fmt.Errorf("invalid array index %q", tok)
+		}
+	}
+	idx, err := strconv.Atoi(tok)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if idx >= n {
+		return 0, fmt.Errorf("array index %d out of range (len %d)", idx, n)
+	}
+	return idx, nil
+}
+
+// kindOf names the JSON type of a parsed value for error messages.
+func kindOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePointer(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  Pointer
+	}{
+		{"root", "", Pointer{}},
+		{"single", "/foo", Pointer{"foo"}},
+		{"nested", "/address/geo/lat", Pointer{"address", "geo", "lat"}},
+		{"empty token", "/", Pointer{""}},
+		{"escaped slash", "/a~1b", Pointer{"a/b"}},
+		{"escaped tilde", "/m~0n", Pointer{"m~n"}},
+		{"escape order", "/~01", Pointer{"~1"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePointer(tc.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("want %q got %q", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("token %d: want %q got %q", i, tc.want[i], got[i])
+				}
+			}
+			if got.String() != tc.input {
+				t.Fatalf("round trip: want %q got %q", tc.input, got.String())
+			}
+		})
+	}
+}
+
+func TestParsePointerInvalid(t *testing.T) {
+	for _, input := range []string{"foo", "/a~", "/a~2"} {
+		if _, err := ParsePointer(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+// examples from RFC 6901 section 5
+func TestPointerGet(t *testing.T) {
+	doc := runParser(`{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`)
+	cases := []struct {
+		ptr  string
+		want any
+	}{
+		{"/foo/0", "bar"},
+		{"/", 0.0},
+		{"/a~1b", 1.0},
+		{"/c%d", 2.0},
+		{"/e^f", 3.0},
+		{"/g|h", 4.0},
+		{"/i\\j", 5.0},
+		{"/k\"l", 6.0},
+		{"/ ", 7.0},
+		{"/m~0n", 8.0},
+	}
+	for _, tc := range cases {
+		p, err := ParsePointer(tc.ptr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.ptr, err)
+		}
+		got, err := p.Get(doc)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.ptr, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: want %v got %v", tc.ptr, tc.want, got)
+		}
+	}
+}
+
+func TestPointerGetErrors(t *testing.T) {
+	doc := runParser(`{"list":[1,2],"name":"x"}`)
+	cases := []struct {
+		ptr  string
+		want string
+	}{
+		{"/missing", "not found"},
+		{"/list/2", "out of range"},
+		{"/list/01", "invalid array index"},
+		{"/list/-", "invalid array index"},
+		{"/name/0", "cannot index into string"},
+	}
+	for _, tc := range cases {
+		p, _ := ParsePointer(tc.ptr)
+		_, err := p.Get(doc)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.ptr, tc.want, err)
+		}
+	}
+}
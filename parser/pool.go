@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Lexers and their read buffers are recycled by the parse functions that
+// own them from start to finish; lexers handed out by NewLexer are not.
+// Pooled lexers intern object keys, each with an Interner of its own that
+// is emptied between documents.
+var lexerPool = sync.Pool{New: func() any {
+	return &Lexer{interner: NewInterner(InternOptions{})}
+}}
+
+// getLexer returns a pooled Lexer reading r, reusing its bufio.Reader.
+func getLexer(r io.Reader, d Dialect) *Lexer {
+	l := lexerPool.Get().(*Lexer)
+	br := l.r
+	if br == nil {
+		br = bufio.NewReader(r)
+	} else {
+		br.Reset(r)
+	}
+	*l = Lexer{r: br, dialect: d, interner: l.interner}
+	return l
+}
+
+// getBytesLexer returns a pooled Lexer reading data like NewLexerBytes.
+func getBytesLexer(data []byte, d Dialect) *Lexer {
+	l := lexerPool.Get().(*Lexer)
+	if data == nil {
+		data = []byte{}
+	}
+	*l = Lexer{r: l.r, src: data, dialect: d, interner: l.interner}
+	return l
+}
+
+// putLexer returns l to the pool. Neither l nor the tokens' positions in
+// its buffer may be used afterwards; token values are copies and stay valid
+// unless the lexer aliased its input.
+func putLexer(l *Lexer) {
+	br := l.r
+	if br != nil {
+		br.Reset(nil)
+	}
+	l.interner.Reset()
+	*l = Lexer{r: br, interner: l.interner}
+	lexerPool.Put(l)
+}
+
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// maxPooledBuffer keeps a single huge document from pinning its buffer.
+const maxPooledBuffer = 1 << 20
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	b.Reset()
+	bufferPool.Put(b)
+}
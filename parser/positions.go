@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Position is a location in the source text. Line and both columns start
+// at 1. Column counts runes; UTF16Column counts UTF-16 code units, as
+// editors speaking LSP expect.
+type Position struct {
+	Offset      int
+	Line        int
+	Column      int
+	UTF16Column int
+}
+
+// Span is the source range of a value or key; End is just past its last
+// byte.
+type Span struct {
+	Start, End Position
+}
+
+// SourceMap records where every value and object key of a parsed document
+// came from, keyed by JSON Pointer.
+type SourceMap struct {
+	src    []byte
+	lines  []int // offsets of line starts, built on first use
+	values map[string]Span
+	keys   map[string]Span
+}
+
+// ParsePositions parses a document in dialect d like ParseDialect and also
+// returns its SourceMap. Callers that do not need positions should keep
+// using ParseDialect, which does no bookkeeping at all.
+func ParsePositions(r io.Reader, d Dialect) (any, *SourceMap, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := &SourceMap{src: src, values: make(map[string]Span), keys: make(map[string]Span)}
+	l := NewLexerDialect(bytes.NewReader(src), d)
+	tok, err := l.NextToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	v, err := m.decode(l, tok, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := l.Expect(TokenEOF); err != nil {
+		return nil, nil, err
+	}
+	return v, m, nil
+}
+
+// decode is DecodeAny with every value's span recorded under path, the
+// value's JSON Pointer in string form.
+func (m *SourceMap) decode(l *Lexer, tok Token, path string) (any, error) {
+	var v any
+	var err error
+	switch tok.Type {
+	case TokenLeftBrace:
+		v, err = m.decodeObject(l, tok, path)
+	case TokenLeftBracket:
+		arr := []any{}
+		err = DecodeArray(l, tok, func(l *Lexer, tok Token) error {
+			elem, err := m.decode(l, tok, path+"/"+strconv.Itoa(len(arr)))
+			arr = append(arr, elem)
+			return err
+		})
+		v = arr
+	default:
+		v, err = DecodeAny(l, tok)
+	}
+	if err != nil {
+		return v, err
+	}
+	// the lexer stops right after the value's last token
+	m.values[path] = Span{Start: tok.Start, End: l.position()}
+	return v, nil
+}
+
+// decodeObject mirrors DecodeObject, which does not expose where each key
+// was.
+func (m *SourceMap) decodeObject(l *Lexer, open Token, path string) (map[string]any, error) {
+	obj := make(map[string]any)
+	if err := l.enter(open); err != nil {
+		return obj, err
+	}
+	defer l.leave()
+	for first := true; ; first = false {
+		tok, err := l.NextToken()
+		if err != nil {
+			return obj, err
+		}
+		if tok.Type == TokenRightBrace && (first || l.dialect != JSON) {
+			return obj, nil
+		}
+		if !isKey(l.dialect, tok) {
+			return obj, &UnexpectedTokenError{Got: tok, Want: "object key"}
+		}
+		key := tok.Value
+		member := path + "/" + EscapePointerToken(key)
+		m.keys[member] = Span{Start: tok.Start, End: tok.End}
+		if _, err := l.Expect(TokenColon); err != nil {
+			return obj, err
+		}
+		if tok, err = l.NextToken(); err != nil {
+			return obj, err
+		}
+		if obj[key], err = m.decode(l, tok, member); err != nil {
+			return obj, err
+		}
+		if tok, err = l.NextToken(); err != nil {
+			return obj, err
+		}
+		switch tok.Type {
+		case TokenComma:
+		case TokenRightBrace:
+			return obj, nil
+		default:
+			return obj, &UnexpectedTokenError{Got: tok, Want: "',' or '}'"}
+		}
+	}
+}
+
+// Value returns the span of the value at pointer.
+func (m *SourceMap) Value(pointer string) (Span, bool) {
+	span, ok := m.values[pointer]
+	return span, ok
+}
+
+// Key returns the span of the key of the object member at pointer,
+// including its quotes.
+func (m *SourceMap) Key(pointer string) (Span, bool) {
+	span, ok := m.keys[pointer]
+	return span, ok
+}
+
+// Position converts any byte offset into the source to a Position, for
+// locations that do not start a value or key.
+func (m *SourceMap) Position(offset int) Position {
+	if m.lines == nil {
+		m.lines = []int{0}
+		for i, b := range m.src {
+			if b == '\n' {
+				m.lines = append(m.lines, i+1)
+			}
+		}
+	}
+	line := sort.SearchInts(m.lines, offset+1) - 1
+	text := m.src[m.lines[line]:offset]
+	pos := Position{Offset: offset, Line: line + 1, Column: 1, UTF16Column: 1}
+	for len(text) > 0 {
+		r, size := utf8.DecodeRune(text)
+		text = text[size:]
+		pos.Column++
+		pos.UTF16Column++
+		if r > 0xffff {
+			pos.UTF16Column++
+		}
+	}
+	return pos
+}
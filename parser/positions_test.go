@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParsePositions(t *testing.T) {
+	input := "{\n  \"name\": \"Zoë\", \"tags\": [1, {\"a\": null}],\n  \"über\": true\n}"
+	got, m, err := ParsePositions(strings.NewReader(input), JSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !Equal(got, runParser(input)) {
+		t.Fatalf("value differs from BasicParase: %#v", got)
+	}
+	tests := []struct {
+		pointer string
+		key     bool
+		text    string
+		start   Position
+	}{
+		{"", false, input, Position{Offset: 0, Line: 1, Column: 1, UTF16Column: 1}},
+		{"/name", true, `"name"`, Position{Offset: 4, Line: 2, Column: 3, UTF16Column: 3}},
+		{"/name", false, `"Zoë"`, Position{Offset: 12, Line: 2, Column: 11, UTF16Column: 11}},
+		{"/tags", false, `[1, {"a": null}]`, Position{Offset: 28, Line: 2, Column: 26, UTF16Column: 26}},
+		{"/tags/0", false, `1`, Position{Offset: 29, Line: 2, Column: 27, UTF16Column: 27}},
+		{"/tags/1/a", false, `null`, Position{Offset: 38, Line: 2, Column: 36, UTF16Column: 36}},
+		{"/über", true, `"über"`, Position{Offset: 48, Line: 3, Column: 3, UTF16Column: 3}},
+		{"/über", false, `true`, Position{Offset: 57, Line: 3, Column: 11, UTF16Column: 11}},
+	}
+	for _, tc := range tests {
+		lookup := m.Value
+		if tc.key {
+			lookup = m.Key
+		}
+		span, ok := lookup(tc.pointer)
+		if !ok {
+			t.Fatalf("%q (key %v): not found", tc.pointer, tc.key)
+		}
+		if span.Start != tc.start {
+			t.Errorf("%q (key %v): start %+v, expected %+v", tc.pointer, tc.key, span.Start, tc.start)
+		}
+		if text := input[span.Start.Offset:span.End.Offset]; text != tc.text {
+			t.Errorf("%q (key %v): spans %q, expected %q", tc.pointer, tc.key, text, tc.text)
+		}
+	}
+	if _, ok := m.Value("/missing"); ok {
+		t.Errorf("expected no span for a missing pointer")
+	}
+	if _, ok := m.Key(""); ok {
+		t.Errorf("the root has no key")
+	}
+}
+
+func TestParsePositionsErrors(t *testing.T) {
+	for _, input := range []string{`{"a" 1}`, `[1, 2`, `{} []`} {
+		if _, _, err := ParsePositions(strings.NewReader(input), JSON); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
+
+func TestParsePositionsTestData(t *testing.T) {
+	data, err := os.ReadFile("../test_data/example_users.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	_, m, err := ParsePositions(strings.NewReader(string(data)), JSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	span, ok := m.Value("/1/address/geo/lat")
+	if !ok {
+		t.Fatalf("lat not found")
+	}
+	if text := string(data[span.Start.Offset:span.End.Offset]); text != `"-43.9509"` {
+		t.Fatalf("unexpected span text %s", text)
+	}
+}
@@ -0,0 +1,512 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Severity grades a Diagnostic.
+type Severity int
+
+const (
+	// SeverityError marks input that is not valid in the dialect.
+	SeverityError Severity = iota
+	// SeverityWarning marks valid input that is probably a mistake, such as
+	// a duplicated object key.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Fix is a suggested edit that resolves a Diagnostic: replace the bytes
+// in [Start, End) with NewText. Insertions have Start == End.
+type Fix struct {
+	Title   string // e.g. "insert ','"
+	Start   int
+	End     int
+	NewText string
+}
+
+// Diagnostic is one problem found by ParseRecover. Start and End are byte
+// offsets of the offending input.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Start    int
+	End      int
+	Fix      *Fix // nil when there is no obvious fix
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s at offset %d", d.Severity, d.Message, d.Start)
+}
+
+// ParseRecover parses a document written in dialect d without stopping at
+// the first syntax error. It resynchronizes at commas and closing brackets,
+// returns every problem it found and a best-effort value in which broken
+// members and elements are dropped or null. The error is only non-nil when
+// reading r fails.
+func ParseRecover(r io.Reader, d Dialect) (any, []Diagnostic, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &recoverParser{src: src, dialect: d}
+	p.tokenize()
+	if p.peek().Type == TokenEOF {
+		p.report(p.peek(), "empty document", nil)
+		return nil, p.diags, nil
+	}
+	v := p.value()
+	if tok := p.peek(); tok.Type != TokenEOF {
+		p.diags = append(p.diags, Diagnostic{
+			Message: "unexpected data after the document",
+			Start:   tok.start,
+			End:     len(src),
+			Fix:     &Fix{Title: "remove trailing data", Start: tok.start, End: len(src)},
+		})
+	}
+	// lexical problems were found before the parser ran
+	sort.SliceStable(p.diags, func(i, j int) bool { return p.diags[i].Start < p.diags[j].Start })
+	return v, p.diags, nil
+}
+
+// spanToken is a token together with its byte offsets in the source.
+type spanToken struct {
+	Token
+	start, end int
+}
+
+type recoverParser struct {
+	src     []byte
+	dialect Dialect
+	toks    []spanToken
+	i       int
+	open    []TokenType // closing tokens of the containers being parsed
+	diags   []Diagnostic
+}
+
+func (p *recoverParser) report(tok spanToken, msg string, fix *Fix) {
+	p.diags = append(p.diags, Diagnostic{Message: msg, Start: tok.start, End: tok.end, Fix: fix})
+}
+
+// tokenize lexes the whole source. Bytes the Lexer rejects are reported and
+// skipped up to the next delimiter; bare words become identifier tokens so
+// the parser can tell an unquoted key from a misspelled literal.
+func (p *recoverParser) tokenize() {
+	base := 0
+	l := NewLexerDialect(bytes.NewReader(p.src), p.dialect)
+	for {
+		tok, err := l.NextToken()
+		start, end := l.Span()
+		start, end = start+base, end+base
+		if err == nil {
+			if tok.Type == TokenEOF {
+				p.toks = append(p.toks, spanToken{Token: tok, start: len(p.src), end: len(p.src)})
+				return
+			}
+			p.checkToken(spanToken{Token: tok, start: start, end: end})
+			continue
+		}
+		end = p.skipInvalid(start, err)
+		base = end
+		l = NewLexerDialect(bytes.NewReader(p.src[end:]), p.dialect)
+	}
+}
+
+// checkToken records problems the Lexer lets through and appends tok.
+func (p *recoverParser) checkToken(tok spanToken) {
+	switch tok.Type {
+	case TokenNumber:
+		if _, err := parseNumber(tok.Value); err != nil {
+			p.report(tok, fmt.Sprintf("invalid number %q", tok.Value), nil)
+		}
+	}
+	p.toks = append(p.toks, tok)
+}
+
+// skipInvalid reports the lexer error for the token starting at start and
+// returns the offset to resume lexing from.
+func (p *recoverParser) skipInvalid(start int, err error) int {
+	rest := p.src[start:]
+	bad := spanToken{start: start}
+	switch {
+	case bytes.HasPrefix(rest, []byte("//")):
+		bad.end = start + lineLength(rest)
+		p.report(bad, "comments are not allowed in JSON", &Fix{Title: "remove the comment", Start: start, End: bad.end})
+		return bad.end
+	case bytes.HasPrefix(rest, []byte("/*")):
+		bad.end = len(p.src)
+		if i := bytes.Index(rest[2:], []byte("*/")); i >= 0 {
+			bad.end = start + i + 4
+			p.report(bad, "comments are not allowed in JSON", &Fix{Title: "remove the comment", Start: start, End: bad.end})
+		} else {
+			p.report(bad, "unterminated block comment", &Fix{Title: "close the comment", Start: bad.end, End: bad.end, NewText: "*/"})
+		}
+		return bad.end
+	case rest[0] == '"' || (rest[0] == '\'' && p.dialect == JSON5):
+		bad.end = start + quotedLength(rest)
+		if raw := p.src[start:bad.end]; len(raw) < 2 || raw[len(raw)-1] != raw[0] {
+			// cut an unclosed string at the end of its line
+			bad.Token = Token{Type: TokenString, Value: string(raw[1:])}
+			p.report(bad, "unterminated string", &Fix{Title: "close the string", Start: bad.end, End: bad.end, NewText: string(raw[0])})
+		} else {
+			// a bad escape: keep the raw contents and resume after the string
+			bad.Token = Token{Type: TokenString, Value: string(raw[1 : len(raw)-1])}
+			p.report(bad, errorMessage(err), nil)
+		}
+		p.toks = append(p.toks, bad)
+		return bad.end
+	case rest[0] == '\'':
+		if i := bytes.IndexAny(rest[1:], "'\n"); i >= 0 && rest[1+i] == '\'' {
+			bad.end = start + i + 2
+			body := string(rest[1 : i+1])
+			bad.Token = Token{Type: TokenString, Value: body}
+			p.report(bad, "strings must use double quotes", &Fix{
+				Title:   "use double quotes",
+				Start:   start,
+				End:     bad.end,
+				NewText: string(AppendString(nil, body)),
+			})
+			p.toks = append(p.toks, bad)
+			return bad.end
+		}
+	}
+	bad.end = start + wordLength(rest)
+	if word := string(p.src[start:bad.end]); isWord(word) {
+		// let the parser decide whether this is an unquoted key
+		bad.Token = Token{Type: TokenIdentifier, Value: word}
+		p.toks = append(p.toks, bad)
+		return bad.end
+	}
+	p.report(bad, errorMessage(err), nil)
+	return bad.end
+}
+
+// errorMessage returns the message of a lexer error without its offset,
+// which a Diagnostic carries separately.
+func errorMessage(err error) string {
+	if se, ok := err.(*SyntaxError); ok {
+		return se.Msg
+	}
+	return err.Error()
+}
+
+func lineLength(b []byte) int {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		return i
+	}
+	return len(b)
+}
+
+// quotedLength returns the length of the string literal at the start of b,
+// stopping at the end of the line if it is not closed.
+func quotedLength(b []byte) int {
+	for i := 1; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case b[0]:
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(b)
+}
+
+// wordLength returns the length of the run of bytes at the start of b up to
+// the next whitespace or JSON delimiter, and at least 1.
+func wordLength(b []byte) int {
+	n := 1
+	for n < len(b) && !strings.ContainsRune(" \t\r\n{}[],:\"'/", rune(b[n])) {
+		n++
+	}
+	return n
+}
+
+func isWord(s string) bool {
+	for i, r := range s {
+		if r == '\\' || !isIdentRune(r, i == 0) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (p *recoverParser) peek() spanToken {
+	return p.toks[p.i]
+}
+
+// take consumes the next token; EOF is never consumed.
+func (p *recoverParser) take() spanToken {
+	tok := p.toks[p.i]
+	if tok.Type != TokenEOF {
+		p.i++
+	}
+	return tok
+}
+
+func startsValue(t TokenType) bool {
+	switch t {
+	case TokenLeftBrace, TokenLeftBracket, TokenString, TokenNumber, TokenTrue, TokenFalse, TokenNull, TokenIdentifier:
+		return true
+	}
+	return false
+}
+
+// value parses one value. When the next token cannot start a value it
+// reports the problem, consumes nothing and returns nil.
+func (p *recoverParser) value() any {
+	tok := p.peek()
+	switch tok.Type {
+	case TokenLeftBrace:
+		return p.object(p.take())
+	case TokenLeftBracket:
+		return p.array(p.take())
+	case TokenString, TokenTrue, TokenFalse, TokenNull:
+		return parseLiteral(p.take().Token)
+	case TokenNumber:
+		p.take()
+		n, err := parseNumber(tok.Value)
+		if err != nil {
+			return nil
+		}
+		return n
+	case TokenIdentifier:
+		p.take()
+		return p.identifierValue(tok)
+	default:
+		p.report(tok, fmt.Sprintf("expected a value, found %v", tok.Type), nil)
+		return nil
+	}
+}
+
+// identifierValue reports a bare word in value position, suggesting the
+// literal it was probably meant to be.
+func (p *recoverParser) identifierValue(tok spanToken) any {
+	for _, lit := range []Token{{Type: TokenTrue, Value: "true"}, {Type: TokenFalse, Value: "false"}, {Type: TokenNull, Value: "null"}} {
+		if strings.EqualFold(tok.Value, lit.Value) {
+			p.report(tok, fmt.Sprintf("invalid literal %q", tok.Value), &Fix{Title: "replace with " + lit.Value, Start: tok.start, End: tok.end, NewText: lit.Value})
+			return parseLiteral(lit)
+		}
+	}
+	p.report(tok, fmt.Sprintf("unquoted string %q", tok.Value), &Fix{
+		Title:   "quote the string",
+		Start:   tok.start,
+		End:     tok.end,
+		NewText: string(AppendString(nil, tok.Value)),
+	})
+	return tok.Value
+}
+
+// closes reports whether t closes one of the containers around the current
+// one, meaning the current container is missing its closing bracket.
+func (p *recoverParser) closes(t TokenType) bool {
+	for i := len(p.open) - 2; i >= 0; i-- {
+		if p.open[i] == t {
+			return true
+		}
+	}
+	return false
+}
+
+// skipTo drops tokens up to the next comma or closer of the current
+// container, stepping over nested containers.
+func (p *recoverParser) skipTo(closer TokenType) {
+	depth := 0
+	for {
+		switch p.peek().Type {
+		case TokenEOF:
+			return
+		case TokenLeftBrace, TokenLeftBracket:
+			depth++
+		case TokenRightBrace, TokenRightBracket:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case TokenComma:
+			if depth == 0 {
+				return
+			}
+		}
+		p.take()
+	}
+}
+
+// separator handles what follows an item of a container closed by closer.
+// It returns false once the container has ended, whether by its closing
+// bracket or by an error that leaves it unclosed.
+func (p *recoverParser) separator(open spanToken, closer TokenType, prevEnd int) bool {
+	for {
+		tok := p.peek()
+		switch {
+		case tok.Type == TokenComma:
+			p.take()
+			if next := p.peek(); next.Type == closer {
+				if p.dialect == JSON {
+					p.report(tok, "trailing comma", &Fix{Title: "remove ','", Start: tok.start, End: tok.end})
+				}
+				p.take()
+				return false
+			}
+			return true
+		case tok.Type == closer:
+			p.take()
+			return false
+		case tok.Type == TokenEOF || p.closes(tok.Type):
+			p.unclosed(open, closer, prevEnd)
+			return false
+		case startsValue(tok.Type):
+			p.diags = append(p.diags, Diagnostic{
+				Message: "missing comma",
+				Start:   prevEnd,
+				End:     tok.start,
+				Fix:     &Fix{Title: "insert ','", Start: prevEnd, End: prevEnd, NewText: ","},
+			})
+			return true
+		default:
+			p.report(tok, fmt.Sprintf("unexpected %v, expected ',' or %v", tok.Type, closer), nil)
+			p.take()
+			p.skipTo(closer)
+		}
+	}
+}
+
+func (p *recoverParser) unclosed(open spanToken, closer TokenType, at int) {
+	bracket := strings.Trim(closer.String(), "'")
+	p.report(open, "unclosed "+strings.Trim(open.Type.String(), "'"), &Fix{Title: "insert '" + bracket + "'", Start: at, End: at, NewText: bracket})
+}
+
+func (p *recoverParser) object(open spanToken) any {
+	obj := make(map[string]any)
+	seen := make(map[string]bool)
+	p.open = append(p.open, TokenRightBrace)
+	defer func() { p.open = p.open[:len(p.open)-1] }()
+	if p.peek().Type == TokenRightBrace {
+		p.take()
+		return obj
+	}
+	for {
+		key, ok := p.key(open)
+		if !ok {
+			if !p.recoverItem(open, TokenRightBrace) {
+				return obj
+			}
+			continue
+		}
+		if colon := p.peek(); colon.Type == TokenColon {
+			p.take()
+		} else if startsValue(colon.Type) {
+			p.diags = append(p.diags, Diagnostic{
+				Message: "missing colon",
+				Start:   key.end,
+				End:     colon.start,
+				Fix:     &Fix{Title: "insert ':'", Start: key.end, End: key.end, NewText: ":"},
+			})
+		} else {
+			p.report(colon, fmt.Sprintf("expected ':' after key %q", key.Value), nil)
+			obj[key.Value] = nil
+			if !p.recoverItem(open, TokenRightBrace) {
+				return obj
+			}
+			continue
+		}
+		v := p.value()
+		if seen[key.Value] {
+			p.diags = append(p.diags, Diagnostic{Severity: SeverityWarning, Message: fmt.Sprintf("duplicate key %q", key.Value), Start: key.start, End: key.end})
+		}
+		seen[key.Value] = true
+		obj[key.Value] = v
+		if !p.separator(open, TokenRightBrace, p.toks[p.i-1].end) {
+			return obj
+		}
+	}
+}
+
+// key reads an object key, reporting unquoted keys outside JSON5.
+func (p *recoverParser) key(open spanToken) (spanToken, bool) {
+	tok := p.peek()
+	switch tok.Type {
+	case TokenString:
+		return p.take(), true
+	case TokenIdentifier:
+		p.take()
+		if p.dialect != JSON5 {
+			p.report(tok, fmt.Sprintf("unquoted key %q", tok.Value), &Fix{
+				Title:   "quote the key",
+				Start:   tok.start,
+				End:     tok.end,
+				NewText: string(AppendString(nil, tok.Value)),
+			})
+		}
+		return tok, true
+	case TokenTrue, TokenFalse, TokenNull, TokenNumber:
+		if p.dialect == JSON5 && isWord(tok.Value) {
+			// JSON5 allows reserved words and Infinity/NaN as keys
+			return p.take(), true
+		}
+	}
+	p.report(tok, fmt.Sprintf("expected a string key, found %v", tok.Type), nil)
+	return tok, false
+}
+
+// recoverItem skips a broken item and reports whether the container goes on.
+func (p *recoverParser) recoverItem(open spanToken, closer TokenType) bool {
+	p.skipTo(closer)
+	tok := p.peek()
+	switch {
+	case tok.Type == TokenComma:
+		p.take()
+		if p.peek().Type == closer {
+			p.take()
+			return false
+		}
+		return true
+	case tok.Type == closer:
+		p.take()
+		return false
+	default:
+		// a closer that belongs to an outer container, or the end of input
+		if tok.Type == TokenEOF || p.closes(tok.Type) {
+			p.unclosed(open, closer, p.toks[max(p.i-1, 0)].end)
+		} else {
+			p.report(tok, fmt.Sprintf("unexpected %v", tok.Type), &Fix{Title: "remove " + tok.Type.String(), Start: tok.start, End: tok.end})
+			p.take()
+			return true
+		}
+		return false
+	}
+}
+
+func (p *recoverParser) array(open spanToken) any {
+	arr := []any{}
+	p.open = append(p.open, TokenRightBracket)
+	defer func() { p.open = p.open[:len(p.open)-1] }()
+	if p.peek().Type == TokenRightBracket {
+		p.take()
+		return arr
+	}
+	for {
+		if !startsValue(p.peek().Type) {
+			p.value() // reports the missing value
+			if !p.recoverItem(open, TokenRightBracket) {
+				return arr
+			}
+			continue
+		}
+		arr = append(arr, p.value())
+		if !p.separator(open, TokenRightBracket, p.toks[p.i-1].end) {
+			return arr
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestParseRecover(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		dialect  Dialect
+		expected any
+		messages []string
+	}{
+		{
+			name:     "valid",
+			input:    `{"a": [1, true, null]}`,
+			expected: map[string]any{"a": []any{1.0, true, nil}},
+		},
+		{
+			name:     "missing comma",
+			input:    `{"a": 1 "b": 2}`,
+			expected: map[string]any{"a": 1.0, "b": 2.0},
+			messages: []string{"missing comma"},
+		},
+		{
+			name:     "missing comma in array",
+			input:    `[1 2, 3]`,
+			expected: []any{1.0, 2.0, 3.0},
+			messages: []string{"missing comma"},
+		},
+		{
+			name:     "unquoted key",
+			input:    `{name: "x", "ok": true}`,
+			expected: map[string]any{"name": "x", "ok": true},
+			messages: []string{`unquoted key "name"`},
+		},
+		{
+			name:     "unquoted key is fine in json5",
+			input:    `{name: "x"}`,
+			dialect:  JSON5,
+			expected: map[string]any{"name": "x"},
+		},
+		{
+			name:     "missing colon",
+			input:    `{"a" 1}`,
+			expected: map[string]any{"a": 1.0},
+			messages: []string{"missing colon"},
+		},
+		{
+			name:     "missing value",
+			input:    `{"a": , "b": 2}`,
+			expected: map[string]any{"a": nil, "b": 2.0},
+			messages: []string{"expected a value, found ','"},
+		},
+		{
+			name:     "trailing comma",
+			input:    `[1, 2,]`,
+			expected: []any{1.0, 2.0},
+			messages: []string{"trailing comma"},
+		},
+		{
+			name:     "trailing comma is fine in jsonc",
+			input:    `[1, 2,]`,
+			dialect:  JSONC,
+			expected: []any{1.0, 2.0},
+		},
+		{
+			name:     "several problems",
+			input:    `{"a": tru, 'b': 2, "c": [1,, 3], "a": 4,}`,
+			expected: map[string]any{"a": 4.0, "b": 2.0, "c": []any{1.0, 3.0}},
+			messages: []string{
+				`unquoted string "tru"`,
+				"strings must use double quotes",
+				"expected a value, found ','",
+				`duplicate key "a"`,
+				"trailing comma",
+			},
+		},
+		{
+			name:     "misspelled literal",
+			input:    `[True, NULL]`,
+			expected: []any{true, nil},
+			messages: []string{`invalid literal "True"`, `invalid literal "NULL"`},
+		},
+		{
+			name:     "unclosed containers",
+			input:    `{"a": [1, 2`,
+			expected: map[string]any{"a": []any{1.0, 2.0}},
+			messages: []string{"unclosed {", "unclosed ["},
+		},
+		{
+			name:     "mismatched bracket",
+			input:    `{"a": [1, 2}`,
+			expected: map[string]any{"a": []any{1.0, 2.0}},
+			messages: []string{"unclosed ["},
+		},
+		{
+			name:     "stray closer",
+			input:    `[1, 2}]`,
+			expected: []any{1.0, 2.0},
+			messages: []string{"unexpected '}', expected ',' or ']'"},
+		},
+		{
+			name:     "comment in strict json",
+			input:    "{\"a\": 1 // one\n}",
+			expected: map[string]any{"a": 1.0},
+			messages: []string{"comments are not allowed in JSON"},
+		},
+		{
+			name:     "unterminated string",
+			input:    "[\"abc\n, 2]",
+			expected: []any{"abc", 2.0},
+			messages: []string{"unterminated string"},
+		},
+		{
+			name:     "bad escape",
+			input:    `["a\qb", 1]`,
+			expected: []any{`a\qb`, 1.0},
+			messages: []string{"invalid escape character: q"},
+		},
+		{
+			name:     "trailing data",
+			input:    `{} {}`,
+			expected: map[string]any{},
+			messages: []string{"unexpected data after the document"},
+		},
+		{
+			name:     "empty",
+			input:    "  ",
+			messages: []string{"empty document"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, diags, err := ParseRecover(strings.NewReader(tc.input), tc.dialect)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !Equal(got, tc.expected) {
+				t.Errorf("value mismatch:\nexpected %#v\ngot      %#v", tc.expected, got)
+			}
+			var messages []string
+			for _, d := range diags {
+				messages = append(messages, d.Message)
+			}
+			if strings.Join(messages, "\n") != strings.Join(tc.messages, "\n") {
+				t.Fatalf("diagnostics mismatch:\nexpected %q\ngot      %q", tc.messages, messages)
+			}
+		})
+	}
+}
+
+// applyFixes applies the suggested fixes of diags, which must not overlap.
+func applyFixes(src string, diags []Diagnostic) string {
+	var fixes []*Fix
+	for _, d := range diags {
+		if d.Fix != nil {
+			fixes = append(fixes, d.Fix)
+		}
+	}
+	// apply from the back so earlier offsets stay valid
+	sort.Slice(fixes, func(i, j int) bool { return fixes[i].Start > fixes[j].Start })
+	for _, f := range fixes {
+		src = src[:f.Start] + f.NewText + src[f.End:]
+	}
+	return src
+}
+
+func TestParseRecoverFixes(t *testing.T) {
+	input := `{name: "x" "tags": ['a', 'b',], "ok": True`
+	_, diags, err := ParseRecover(strings.NewReader(input), JSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fixed := applyFixes(input, diags)
+	expected := `{"name": "x", "tags": ["a", "b"], "ok": true}`
+	if fixed != expected {
+		t.Fatalf("fixed mismatch:\nexpected %s\ngot      %s", expected, fixed)
+	}
+	if _, err := ParseDialect(strings.NewReader(fixed), JSON); err != nil {
+		t.Fatalf("fixed document does not parse: %v", err)
+	}
+}
+
+func TestParseRecoverSpans(t *testing.T) {
+	input := `[1, 2 3]`
+	_, diags, _ := ParseRecover(strings.NewReader(input), JSON)
+	if len(diags) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %v", diags)
+	}
+	d := diags[0]
+	if d.Start != 5 || d.End != 6 || d.Severity != SeverityError {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+	if d.String() != "error: missing comma at offset 5" {
+		t.Errorf("unexpected string %q", d.String())
+	}
+}
+
+func TestParseRecoverTestData(t *testing.T) {
+	for _, name := range []string{"albums", "posts", "todos", "users"} {
+		data, err := os.ReadFile("../test_data/example_" + name + ".json")
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+		got, diags, err := ParseRecover(strings.NewReader(string(data)), JSON)
+		if err != nil || len(diags) != 0 {
+			t.Fatalf("%s: unexpected diagnostics %v (err %v)", name, diags, err)
+		}
+		if !Equal(got, runParser(string(data))) {
+			t.Fatalf("%s: value differs from BasicParase", name)
+		}
+	}
+}
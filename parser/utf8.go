@@ -0,0 +1,93 @@
+package parser
+
+import "unicode/utf8"
+
+// UTF8Policy says what happens to invalid UTF-8 in strings. RFC 8259
+// requires JSON text to be UTF-8, but producers get it wrong, e.g. by
+// cutting strings in the middle of a rune.
+type UTF8Policy int
+
+const (
+	// UTF8Replace replaces every byte that does not start a valid UTF-8
+	// sequence with U+FFFD, as encoding/json does. It is the default.
+	UTF8Replace UTF8Policy = iota
+	// UTF8Reject makes the lexer fail with a *SyntaxError at the first
+	// invalid byte, and the encoder with an error.
+	UTF8Reject
+	// UTF8Pass keeps invalid bytes as they are.
+	UTF8Pass
+)
+
+func (p UTF8Policy) String() string {
+	switch p {
+	case UTF8Replace:
+		return "replace"
+	case UTF8Reject:
+		return "reject"
+	case UTF8Pass:
+		return "pass"
+	}
+	return "UTF8Policy(?)"
+}
+
+// WithUTF8 sets the policy for invalid UTF-8 in the strings l reads, keys
+// and JSON5 identifiers included. WithUTF8 returns l.
+func (l *Lexer) WithUTF8(p UTF8Policy) *Lexer {
+	l.utf8 = p
+	return l
+}
+
+// invalidUTF8 reports whether the byte the lexer just read from src starts
+// an invalid sequence, and otherwise consumes the rest of its rune.
+func (l *Lexer) invalidUTF8() bool {
+	r, size := utf8.DecodeRune(l.src[l.pos-1:])
+	if r == utf8.RuneError && size == 1 {
+		return true
+	}
+	for range size - 1 {
+		l.next()
+	}
+	return false
+}
+
+// decodeRune decodes the rune starting with lead, a byte of at least
+// utf8.RuneSelf the lexer just read, without consuming the rest of it.
+// Unless the lexer peeked since, lead can still be unread.
+func (l *Lexer) decodeRune(lead byte) (r rune, size int) {
+	if l.src != nil {
+		return utf8.DecodeRune(l.src[l.pos-1:])
+	}
+	// bufio cannot unread a byte once it has peeked, so peek from lead on
+	if l.r.UnreadByte() == nil {
+		buf, _ := l.r.Peek(utf8.UTFMax)
+		r, size = utf8.DecodeRune(buf)
+		_, _ = l.r.ReadByte()
+		return r, size
+	}
+	var buf [utf8.UTFMax]byte
+	buf[0] = lead
+	rest, _ := l.r.Peek(utf8.UTFMax - 1)
+	n := 1 + copy(buf[1:], rest)
+	return utf8.DecodeRune(buf[:n])
+}
+
+// lexRune appends the rune starting with lead, a byte of at least
+// utf8.RuneSelf the lexer just read, to str, applying the lexer's policy
+// if it does not start a valid sequence.
+func (l *Lexer) lexRune(str []byte, lead byte) ([]byte, error) {
+	r, size := l.decodeRune(lead)
+	if r != utf8.RuneError || size > 1 {
+		str = utf8.AppendRune(str, r)
+		for range size - 1 {
+			l.next()
+		}
+		return str, nil
+	}
+	switch l.utf8 {
+	case UTF8Reject:
+		return nil, &SyntaxError{Msg: "invalid UTF-8 in string", Offset: l.pos - 1}
+	case UTF8Replace:
+		return utf8.AppendRune(str, utf8.RuneError), nil
+	}
+	return append(str, lead), nil
+}
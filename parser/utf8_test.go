@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLexUTF8Policy(t *testing.T) {
+	cases := []struct {
+		name    string
+		dialect Dialect
+		input   string
+		policy  UTF8Policy
+		want    string
+		offset  int // of the SyntaxError, or -1
+	}{
+		{"valid", JSON, `"héllo 😀"`, UTF8Reject, "héllo 😀", -1},
+		{"replace lone continuation", JSON, "\"a\x80b\"", UTF8Replace, "a\uFFFDb", -1},
+		{"replace each byte", JSON, "\"\xff\xfe\"", UTF8Replace, "\uFFFD\uFFFD", -1},
+		{"replace truncated rune", JSON, "\"\xe2\x82\"", UTF8Replace, "\uFFFD\uFFFD", -1},
+		{"replace encoded surrogate", JSON, "\"\xed\xa0\x80\"", UTF8Replace, "\uFFFD\uFFFD\uFFFD", -1},
+		{"replace after escape", JSON, "\"\\n\xc0\"", UTF8Replace, "\n\uFFFD", -1},
+		{"pass", JSON, "\"a\x80b\"", UTF8Pass, "a\x80b", -1},
+		{"reject", JSON, "\"ab\xffc\"", UTF8Reject, "", 3},
+		{"reject after escape", JSON, "\"\\t\xe2\x82\"", UTF8Reject, "", 3},
+		{"reject overlong", JSON, "\"é\xc0\xaf\"", UTF8Reject, "", 3},
+		{"reject single quoted", JSON5, "'\x80'", UTF8Reject, "", 1},
+		{"identity escape", JSON5, `'\é'`, UTF8Replace, "é", -1},
+		{"identity escape in text", JSON5, `'a\€b'`, UTF8Replace, "a€b", -1},
+		{"identity escape outside the BMP", JSON5, `"\😀"`, UTF8Reject, "😀", -1},
+		{"reject identity escape", JSON5, `'\é'`, UTF8Reject, "é", -1},
+		{"reject invalid identity escape", JSON5, "'\\\xff'", UTF8Reject, "", 2},
+		{"replace invalid identity escape", JSON5, "'\\\xffa'", UTF8Replace, "\uFFFDa", -1},
+		{"replace identifier", JSON5, "a\x80", UTF8Replace, "a\uFFFD", -1},
+		{"reject identifier", JSON5, "a\x80", UTF8Reject, "", 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			lexers := map[string]*Lexer{
+				"reader": NewLexerDialect(bytes.NewReader([]byte(tc.input)), tc.dialect),
+				"bytes":  NewLexerBytes([]byte(tc.input), tc.dialect),
+			}
+			for name, l := range lexers {
+				tok, err := l.WithUTF8(tc.policy).NextToken()
+				if tc.offset >= 0 {
+					var serr *SyntaxError
+					if !errors.As(err, &serr) || serr.Offset != tc.offset {
+						t.Fatalf("%s: want a SyntaxError at offset %d, got %v, %v", name, tc.offset, tok, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", name, err)
+				}
+				if tok.Value != tc.want {
+					t.Fatalf("%s: want %q got %q", name, tc.want, tok.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestUTF8PolicyKeepsPositions(t *testing.T) {
+	input := []byte("[\"\xff\xfe\", \"é\"]")
+	for _, policy := range []UTF8Policy{UTF8Replace, UTF8Pass} {
+		l := NewLexerBytes(input, JSON).WithUTF8(policy)
+		var last Token
+		for {
+			tok, err := l.NextToken()
+			if err != nil {
+				t.Fatalf("%v: unexpected error: %v", policy, err)
+			}
+			if tok.Type == TokenEOF {
+				break
+			}
+			last = tok
+		}
+		if last.Type != TokenRightBracket || last.Start.Offset != len(input)-1 || last.Start.Column != 11 {
+			t.Fatalf("%v: closing bracket at %+v", policy, last.Start)
+		}
+	}
+}
+
+func TestOpenFileUTF8(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "latin1.json")
+	if err := os.WriteFile(path, []byte("{\"k\": \"\xc3\"}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := OpenFile(path, FileOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := f.Value.(map[string]any)["k"]; got != "\uFFFD" {
+		t.Fatalf("want U+FFFD got %q", got)
+	}
+	var serr *SyntaxError
+	if _, err := OpenFile(path, FileOptions{UTF8: UTF8Reject}); !errors.As(err, &serr) || serr.Offset != 7 {
+		t.Fatalf("want a SyntaxError at offset 7, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"json-parser/diff"
+	"json-parser/parser"
+	"json-parser/schema"
+)
+
+var diffCommand = &command{
+	name:    "diff",
+	args:    "<a> <b>",
+	summary: "compare two documents; exits 1 when they differ",
+	run:     runDiff,
+}
+
+func runDiff(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	arrays := fs.String("arrays", "index", "array alignment: index, lcs, or key to match objects by --key")
+	key := fs.String("key", "id", "member identifying array elements with --arrays key")
+	tolerance := fs.Float64("tolerance", 0, "treat numbers within this absolute difference as equal")
+	format := fs.String("format", "text", "output `format`: text, or patch for an RFC 6902 JSON Patch")
+	color := fs.Bool("color", false, "color the text output")
+	jsonOut := fs.Bool("json", false, "print a JSON report instead of text")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	opts := diff.Options{Key: *key, Tolerance: *tolerance}
+	switch *arrays {
+	case "index":
+		opts.Arrays = diff.ByIndex
+	case "lcs":
+		opts.Arrays = diff.ByLCS
+	case "key":
+		opts.Arrays = diff.ByKey
+	default:
+		return c.usageError(fs, "unknown array mode %q", *arrays)
+	}
+	if *format != "text" && *format != "patch" {
+		return c.usageError(fs, "unknown format %q", *format)
+	}
+	docs, code := c.readDocuments(fs, args, *dialect, 2)
+	if docs == nil {
+		return code
+	}
+	res := diff.Compare(docs[0], docs[1], opts)
+	switch {
+	case *jsonOut:
+		code = c.writeIndented(res.Report())
+	case *format == "patch":
+		code = c.writeIndented(parser.PatchValue(res.Patch))
+	default:
+		fmt.Fprint(c.stdout, res.Text(*color))
+	}
+	if code == exitOK && !res.Equal() {
+		code = exitInvalid
+	}
+	return code
+}
+
+var patchCommand = &command{
+	name:    "patch",
+	args:    "<doc> <patch>",
+	summary: "apply an RFC 6902 JSON Patch, or a merge patch with --merge",
+	run:     runPatch,
+}
+
+func runPatch(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	merge := fs.Bool("merge", false, "treat the patch as an RFC 7396 merge patch")
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	docs, code := c.readDocuments(fs, args, *dialect, 2)
+	if docs == nil {
+		return code
+	}
+	if *merge {
+		return c.writeIndented(parser.MergePatch(docs[0], docs[1]))
+	}
+	ops, err := parser.DecodePatch(docs[1])
+	if err != nil {
+		fmt.Fprintf(c.stderr, "%s: %v\n", args[1], err)
+		return exitInvalid
+	}
+	out, err := parser.ApplyPatch(docs[0], ops)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "%s: %v\n", args[1], err)
+		return exitInvalid
+	}
+	return c.writeIndented(out)
+}
+
+var mergePatchCommand = &command{
+	name:    "merge-patch",
+	args:    "<target> <patch>",
+	summary: "apply an RFC 7396 merge patch",
+	run: func(c *cli, cmd *command, args []string) int {
+		return runMergePatch(c, cmd, args, parser.MergePatch)
+	},
+}
+
+var createMergePatchCommand = &command{
+	name:    "create-merge-patch",
+	args:    "<original> <modified>",
+	summary: "print the merge patch turning one document into another",
+	run: func(c *cli, cmd *command, args []string) int {
+		return runMergePatch(c, cmd, args, parser.CreateMergePatch)
+	},
+}
+
+// runMergePatch reads two documents, combines them with op and prints the
+// result.
+func runMergePatch(c *cli, cmd *command, args []string, op func(a, b any) any) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	docs, code := c.readDocuments(fs, args, *dialect, 2)
+	if docs == nil {
+		return code
+	}
+	return c.writeIndented(op(docs[0], docs[1]))
+}
+
+var inferSchemaCommand = &command{
+	name:    "infer-schema",
+	args:    "<sample>...",
+	summary: "print a JSON Schema describing all samples",
+	run:     runInferSchema,
+}
+
+func runInferSchema(c *cli, cmd *command, args []string) int {
+	fs := c.flagSet(cmd)
+	dialect := dialectFlag(fs)
+	args, code, ok := c.parseFlags(fs, args)
+	if !ok {
+		return code
+	}
+	if len(args) == 0 {
+		return c.usageError(fs, "expected at least one sample file")
+	}
+	samples, code := c.readDocuments(fs, args, *dialect, -1)
+	if samples == nil {
+		return code
+	}
+	return c.writeIndented(schema.Infer(schema.InferOptions{}, samples...))
+}
+
+// readDocuments reads and parses the files named by args, which must be n
+// of them unless n is negative, in which case glob patterns are expanded.
+// On failure it reports the problem and returns a nil slice and the exit
+// code.
+func (c *cli) readDocuments(fs *flag.FlagSet, args []string, dialect string, n int) ([]any, int) {
+	if n >= 0 && len(args) != n {
+		return nil, c.usageError(fs, "expected %d files, got %d", n, len(args))
+	}
+	if n < 0 {
+		var err error
+		if args, err = expandArgs(args); err != nil {
+			return nil, c.usageError(fs, "%v", err)
+		}
+	}
+	docs := make([]any, 0, len(args))
+	stdin := false
+	for _, arg := range args {
+		if arg == "-" {
+			if stdin {
+				return nil, c.usageError(fs, "stdin (-) can be read only once")
+			}
+			stdin = true
+		}
+		in, err := c.readInput(arg, dialect)
+		if err != nil {
+			return nil, c.usageError(fs, "%v", err)
+		}
+		doc, err := in.parse()
+		if err != nil {
+			return nil, c.fail(in, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, exitOK
+}
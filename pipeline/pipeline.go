@@ -0,0 +1,322 @@
+// Package pipeline parses many JSON sources concurrently. Each source is
+// parsed by one of a bounded pool of workers and split into records: the
+// elements of a top-level array, or the whole document otherwise. Records
+// are converted to a caller-chosen type and delivered on a channel, either
+// as soon as they are ready or in source order.
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"json-parser/parser"
+	"os"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// Source is one input of a pipeline.
+type Source struct {
+	Name string // used in records and errors, usually the path
+	Open func() (io.ReadCloser, error)
+}
+
+// Files returns a source for each path.
+func Files(paths ...string) []Source {
+	sources := make([]Source, len(paths))
+	for i, path := range paths {
+		sources[i] = Source{Name: path, Open: func() (io.ReadCloser, error) { return os.Open(path) }}
+	}
+	return sources
+}
+
+// Record is one value read from a source.
+type Record[T any] struct {
+	Source string
+	Index  int // position of the record in its source's top-level array
+	Value  T
+}
+
+// Error is a failure to open, parse or decode part of a source. The other
+// records of the pipeline are not affected.
+type Error struct {
+	Source string
+	Record int // index of the record that failed to decode, or -1
+	Offset int // byte offset of a syntax error, or -1 when unknown
+	Err    error
+}
+
+func (e *Error) Error() string {
+	if e.Record >= 0 {
+		return fmt.Sprintf("%s: record %d: %v", e.Source, e.Record, e.Err)
+	}
+	if e.Offset >= 0 {
+		msg := e.Err.Error()
+		if serr, ok := e.Err.(*parser.SyntaxError); ok {
+			// its message already ends in the offset
+			msg = serr.Msg
+		}
+		return fmt.Sprintf("%s: offset %d: %s", e.Source, e.Offset, msg)
+	}
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// ParseFunc parses the contents of the named source.
+type ParseFunc func(ctx context.Context, name string, r io.Reader) (any, error)
+
+// Options configure Run. The zero value runs one worker per CPU, parses
+// plain JSON and delivers records unordered.
+type Options struct {
+	// Workers bounds the number of sources parsed at the same time.
+	Workers int
+	// Ordered delivers every record of a source before those of the next
+	// one, in the order of the sources slice. Errors are never reordered.
+	Ordered bool
+	// Parse reads one source; nil parses plain JSON with
+	// parser.ParseContext.
+	Parse ParseFunc
+	// Interner, when set, is shared by the default parser of every worker
+	// so that keys repeated across sources are kept once. It must come from
+	// parser.NewSharedInterner. Without it keys are interned per source.
+	Interner *parser.Interner
+}
+
+// Run parses sources on a pool of workers and returns a channel of records
+// and a channel of *Error. decode converts each record; when nil, records
+// are converted with a type assertion to T. Both channels are closed once
+// every source is done or ctx is canceled, and both must be drained: a
+// worker blocks until its record or error is received.
+func Run[T any](ctx context.Context, sources []Source, decode func(any) (T, error), opts Options) (<-chan Record[T], <-chan error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = max(min(workers, len(sources)), 1)
+	if decode == nil {
+		decode = assertType[T]
+	}
+	parse := opts.Parse
+	if parse == nil {
+		parse = func(ctx context.Context, _ string, r io.Reader) (any, error) {
+			return parser.ParseContext(ctx, r)
+		}
+		if opts.Interner != nil {
+			parse = internedParse(opts.Interner)
+		}
+	}
+	p := &pipeline[T]{
+		ctx:     ctx,
+		sources: sources,
+		parse:   parse,
+		decode:  decode,
+		records: make(chan Record[T], workers),
+		errs:    make(chan error, workers),
+	}
+	if opts.Ordered {
+		// each source gets its own lane, drained in order by p.merge
+		p.lanes = make([]chan Record[T], len(sources))
+		for i := range p.lanes {
+			p.lanes[i] = make(chan Record[T], 1)
+		}
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range sources {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				p.process(i)
+			}
+		}()
+	}
+	merged := make(chan struct{})
+	if p.lanes != nil {
+		go func() {
+			defer close(merged)
+			p.merge()
+		}()
+	} else {
+		close(merged)
+	}
+	go func() {
+		wg.Wait()
+		<-merged
+		close(p.records)
+		close(p.errs)
+	}()
+	return p.records, p.errs
+}
+
+type pipeline[T any] struct {
+	ctx     context.Context
+	sources []Source
+	parse   ParseFunc
+	decode  func(any) (T, error)
+	records chan Record[T]
+	errs    chan error
+	lanes   []chan Record[T] // per source when ordered
+}
+
+// process reads the records of source i. It always returns, closing the
+// lane of the source if there is one, so that a failing source can never
+// stall the pipeline.
+func (p *pipeline[T]) process(i int) {
+	out := p.records
+	if p.lanes != nil {
+		out = p.lanes[i]
+		defer close(out)
+	}
+	src := p.sources[i]
+	items, err := p.read(src)
+	if err != nil && p.ctx.Err() != nil {
+		// canceled: the caller knows, and the error is not about the source
+		return
+	}
+	if err != nil {
+		p.fail(&Error{Source: src.Name, Record: -1, Offset: offsetOf(err), Err: err})
+		return
+	}
+	for j, item := range items {
+		if p.ctx.Err() != nil {
+			return
+		}
+		v, err := p.decode(item)
+		if err != nil {
+			if !p.fail(&Error{Source: src.Name, Record: j, Offset: -1, Err: err}) {
+				return
+			}
+			continue
+		}
+		select {
+		case out <- Record[T]{Source: src.Name, Index: j, Value: v}:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+// read parses a source and splits it into records.
+func (p *pipeline[T]) read(src Source) ([]any, error) {
+	f, err := src.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	doc, err := p.parse(p.ctx, src.Name, f)
+	if err != nil {
+		return nil, err
+	}
+	if items, ok := doc.([]any); ok {
+		return items, nil
+	}
+	return []any{doc}, nil
+}
+
+// fail delivers an error and reports whether the pipeline is still running.
+func (p *pipeline[T]) fail(err *Error) bool {
+	select {
+	case p.errs <- err:
+		return true
+	case <-p.ctx.Done():
+		return false
+	}
+}
+
+// merge forwards the lanes to the output one source at a time.
+func (p *pipeline[T]) merge() {
+	for _, lane := range p.lanes {
+		for p.ctx.Err() == nil {
+			var rec Record[T]
+			var ok bool
+			select {
+			case rec, ok = <-lane:
+			case <-p.ctx.Done():
+				return
+			}
+			if !ok {
+				break
+			}
+			select {
+			case p.records <- rec:
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func assertType[T any](v any) (T, error) {
+	t, ok := v.(T)
+	if !ok && (v != nil || any(t) != nil) {
+		// null is only acceptable when T is an interface type
+		return t, fmt.Errorf("cannot use %s as %v", kindOf(v), reflect.TypeFor[T]())
+	}
+	return t, nil
+}
+
+func kindOf(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// offsetOf extracts the input offset from the errors of the parser package.
+func offsetOf(err error) int {
+	var serr *parser.SyntaxError
+	if errors.As(err, &serr) {
+		return serr.Offset
+	}
+	var terr *parser.UnexpectedTokenError
+	if errors.As(err, &terr) && terr.Got.Start.Line > 0 {
+		return terr.Got.Start.Offset
+	}
+	return -1
+}
+
+// internedParse returns a ParseFunc like the default one that takes keys
+// from in.
+func internedParse(in *parser.Interner) ParseFunc {
+	return func(ctx context.Context, _ string, r io.Reader) (any, error) {
+		l := parser.NewLexer(r).WithContext(ctx).WithInterner(in)
+		tok, err := l.NextToken()
+		if err != nil {
+			return nil, err
+		}
+		v, err := parser.DecodeAny(l, tok)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := l.Expect(parser.TokenEOF); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+}
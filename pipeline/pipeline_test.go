@@ -0,0 +1,211 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"json-parser/parser"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var fixtures = []string{
+	"../test_data/example_albums.json",
+	"../test_data/example_posts.json",
+	"../test_data/example_todos.json",
+	"../test_data/example_users.json",
+}
+
+func stringSource(name, data string) Source {
+	return Source{Name: name, Open: func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(data)), nil
+	}}
+}
+
+// collect drains both channels, failing the test if they are not closed in
+// time.
+func collect[T any](t *testing.T, records <-chan Record[T], errs <-chan error) ([]Record[T], []*Error) {
+	t.Helper()
+	var recs []Record[T]
+	var perrs []*Error
+	timeout := time.After(10 * time.Second)
+	for records != nil || errs != nil {
+		select {
+		case rec, ok := <-records:
+			if !ok {
+				records = nil
+				continue
+			}
+			recs = append(recs, rec)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			perrs = append(perrs, err.(*Error))
+		case <-timeout:
+			t.Fatalf("pipeline did not finish")
+		}
+	}
+	return recs, perrs
+}
+
+func sequential(t *testing.T, paths []string) []any {
+	var out []any
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, parser.BasicParase(f).([]any)...)
+		f.Close()
+	}
+	return out
+}
+
+func TestRunOrdered(t *testing.T) {
+	want := sequential(t, fixtures)
+	for _, workers := range []int{1, 2, 8} {
+		records, errs := Run[any](context.Background(), Files(fixtures...), nil, Options{Workers: workers, Ordered: true})
+		recs, perrs := collect(t, records, errs)
+		if len(perrs) > 0 {
+			t.Fatalf("workers=%d: unexpected errors %v", workers, perrs)
+		}
+		got := make([]any, len(recs))
+		for i, rec := range recs {
+			got[i] = rec.Value
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("workers=%d: records differ from sequential parse", workers)
+		}
+	}
+}
+
+func TestRunUnordered(t *testing.T) {
+	records, errs := Run(context.Background(), Files(fixtures...), func(v any) (map[string]any, error) {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, errors.New("not an object")
+		}
+		return obj, nil
+	}, Options{Workers: 3})
+	recs, perrs := collect(t, records, errs)
+	if len(perrs) > 0 {
+		t.Fatalf("unexpected errors %v", perrs)
+	}
+	perSource := map[string]int{}
+	for _, rec := range recs {
+		if rec.Index != perSource[rec.Source] {
+			t.Fatalf("records of one source out of order: got index %d, want %d", rec.Index, perSource[rec.Source])
+		}
+		perSource[rec.Source]++
+	}
+	want := map[string]int{fixtures[0]: 100, fixtures[1]: 100, fixtures[2]: 200, fixtures[3]: 10}
+	if !reflect.DeepEqual(perSource, want) {
+		t.Fatalf("records per source %v, want %v", perSource, want)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	sources := []Source{
+		stringSource("ok", `[1, 2]`),
+		stringSource("syntax", `[1, 2,, 3]`),
+		{Name: "missing", Open: func() (io.ReadCloser, error) { return os.Open("does-not-exist.json") }},
+		stringSource("mixed", `[3, "x", null, 4]`),
+		stringSource("scalar", `5`),
+	}
+	for _, ordered := range []bool{false, true} {
+		records, errs := Run[float64](context.Background(), sources, nil, Options{Workers: 2, Ordered: ordered})
+		recs, perrs := collect(t, records, errs)
+		sum := 0.0
+		for _, rec := range recs {
+			sum += rec.Value
+		}
+		if len(recs) != 5 || sum != 15 {
+			t.Fatalf("ordered=%v: got records %v", ordered, recs)
+		}
+		if ordered {
+			var order []string
+			for _, rec := range recs {
+				order = append(order, rec.Source)
+			}
+			if want := []string{"ok", "ok", "mixed", "mixed", "scalar"}; !reflect.DeepEqual(order, want) {
+				t.Fatalf("record order %v, want %v", order, want)
+			}
+		}
+		byKey := map[string]*Error{}
+		for _, err := range perrs {
+			byKey[err.Source+"/"+strings.Repeat("*", err.Record+1)] = err
+		}
+		if len(perrs) != 4 {
+			t.Fatalf("ordered=%v: got errors %v", ordered, perrs)
+		}
+		if err := byKey["syntax/"]; err == nil || err.Offset != 6 || !strings.HasPrefix(err.Error(), "syntax: offset 6: ") {
+			t.Fatalf("syntax error %v, want offset 6", err)
+		}
+		if err := byKey["missing/"]; err == nil || err.Offset != -1 || !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("missing file error %v", err)
+		}
+		if err := byKey["mixed/**"]; err == nil || err.Error() != "mixed: record 1: cannot use string as float64" {
+			t.Fatalf("decode error %v", err)
+		}
+		if err := byKey["mixed/***"]; err == nil || err.Error() != "mixed: record 2: cannot use null as float64" {
+			t.Fatalf("decode error %v", err)
+		}
+	}
+}
+
+func TestRunNullAsAny(t *testing.T) {
+	records, errs := Run[any](context.Background(), []Source{stringSource("nulls", `[null, {"a": null}]`)}, nil, Options{})
+	recs, perrs := collect(t, records, errs)
+	if len(perrs) > 0 || len(recs) != 2 || recs[0].Value != nil {
+		t.Fatalf("got records %v, errors %v", recs, perrs)
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	sources := make([]Source, 50)
+	for i := range sources {
+		sources[i] = Files(fixtures[2])[0]
+	}
+	for _, ordered := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		records, errs := Run[any](ctx, sources, nil, Options{Workers: 4, Ordered: ordered})
+		n := 0
+		for range records {
+			if n++; n == 10 {
+				cancel()
+				break
+			}
+		}
+		// buffered records and sends already under way may still arrive,
+		// then both channels close
+		recs, _ := collect(t, records, errs)
+		if len(recs) > 2*4+1 {
+			t.Fatalf("ordered=%v: %d records delivered after cancel", ordered, len(recs))
+		}
+		cancel()
+	}
+}
+
+func TestRunSharedInterner(t *testing.T) {
+	in := parser.NewSharedInterner(parser.InternOptions{})
+	records, errs := Run[map[string]any](context.Background(), Files(fixtures[2], fixtures[2]), nil, Options{Workers: 2, Ordered: true, Interner: in})
+	recs, perrs := collect(t, records, errs)
+	if len(perrs) > 0 || len(recs) != 400 {
+		t.Fatalf("got %d records, errors %v", len(recs), perrs)
+	}
+	want := sequential(t, []string{fixtures[2], fixtures[2]})
+	for i, rec := range recs {
+		if !reflect.DeepEqual(rec.Value, want[i]) {
+			t.Fatalf("record %d: got %v, want %v", i, rec.Value, want[i])
+		}
+	}
+	// the todos have four distinct keys
+	if in.Len() != 4 {
+		t.Fatalf("%d keys interned, want 4", in.Len())
+	}
+}
@@ -0,0 +1,241 @@
+package schema
+
+import (
+	"regexp"
+	"sort"
+)
+
+// InferOptions tunes Infer. The zero value uses the defaults noted below.
+type InferOptions struct {
+	// MaxEnumValues is the largest number of distinct strings that is still
+	// reported as an enum (default 5). Negative disables enum detection.
+	MaxEnumValues int
+	// MinEnumRepeat is how many times each distinct string must occur on
+	// average before the field counts as low-cardinality (default 2).
+	MinEnumRepeat int
+}
+
+const (
+	defaultMaxEnumValues = 5
+	defaultMinEnumRepeat = 2
+)
+
+// Infer builds a JSON Schema describing every sample. Types are merged
+// across samples and array elements, object members present in every
+// observed object become required, and members that were null at least once
+// are made nullable. The result is in the parser's value representation, so
+// it can be passed to parser.Marshal or Compile.
+func Infer(opts InferOptions, samples ...any) map[string]any {
+	if opts.MaxEnumValues == 0 {
+		opts.MaxEnumValues = defaultMaxEnumValues
+	}
+	if opts.MinEnumRepeat == 0 {
+		opts.MinEnumRepeat = defaultMinEnumRepeat
+	}
+	root := &shape{}
+	for _, s := range samples {
+		root.observe(s, opts)
+	}
+	out := root.schema(opts)
+	out["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	return out
+}
+
+// shape accumulates what has been seen at one location of the samples.
+type shape struct {
+	nulls, bools, ints, floats int
+
+	strings  int
+	values   map[string]int // distinct strings, dropped once past the enum limit
+	tooMany  bool
+	formats  map[string]int // how many strings matched each format
+	arrays   int
+	items    *shape
+	objects  int
+	props    map[string]*shape
+	propSeen map[string]int // objects that contained the member
+}
+
+func (s *shape) observe(v any, opts InferOptions) {
+	switch x := v.(type) {
+	case nil:
+		s.nulls++
+	case bool:
+		s.bools++
+	case float64:
+		if x == float64(int64(x)) {
+			s.ints++
+		} else {
+			s.floats++
+		}
+	case string:
+		s.observeString(x, opts)
+	case []any:
+		s.arrays++
+		if s.items == nil {
+			s.items = &shape{}
+		}
+		for _, item := range x {
+			s.items.observe(item, opts)
+		}
+	case map[string]any:
+		s.objects++
+		if s.props == nil {
+			s.props = make(map[string]*shape)
+			s.propSeen = make(map[string]int)
+		}
+		for k, child := range x {
+			p, ok := s.props[k]
+			if !ok {
+				p = &shape{}
+				s.props[k] = p
+			}
+			s.propSeen[k]++
+			p.observe(child, opts)
+		}
+	}
+}
+
+func (s *shape) observeString(x string, opts InferOptions) {
+	s.strings++
+	if s.formats == nil {
+		s.formats = make(map[string]int)
+		s.values = make(map[string]int)
+	}
+	for _, f := range detectedFormats {
+		if f.match(x) {
+			s.formats[f.name]++
+		}
+	}
+	if s.tooMany || opts.MaxEnumValues < 0 {
+		return
+	}
+	s.values[x]++
+	if len(s.values) > opts.MaxEnumValues {
+		s.tooMany = true
+		s.values = nil
+	}
+}
+
+func (s *shape) schema(opts InferOptions) map[string]any {
+	out := make(map[string]any)
+	var types []any
+	if s.objects > 0 {
+		types = append(types, "object")
+	}
+	if s.arrays > 0 {
+		types = append(types, "array")
+	}
+	if s.strings > 0 {
+		types = append(types, "string")
+	}
+	switch {
+	case s.floats > 0:
+		types = append(types, "number")
+	case s.ints > 0:
+		types = append(types, "integer")
+	}
+	if s.bools > 0 {
+		types = append(types, "boolean")
+	}
+	if s.nulls > 0 {
+		types = append(types, "null")
+	}
+	switch len(types) {
+	case 0:
+		// nothing observed, e.g. the items of empty arrays: anything goes
+		return out
+	case 1:
+		out["type"] = types[0]
+	default:
+		out["type"] = types
+	}
+
+	if s.objects > 0 {
+		props := make(map[string]any, len(s.props))
+		var required []any
+		for _, k := range sortedNames(s.props) {
+			props[k] = s.props[k].schema(opts)
+			if s.propSeen[k] == s.objects {
+				required = append(required, k)
+			}
+		}
+		out["properties"] = props
+		if len(required) > 0 {
+			out["required"] = required
+		}
+	}
+	if s.arrays > 0 && s.items != nil {
+		if items := s.items.schema(opts); len(items) > 0 {
+			out["items"] = items
+		}
+	}
+	if s.strings > 0 {
+		s.stringConstraints(out, opts)
+	}
+	return out
+}
+
+func (s *shape) stringConstraints(out map[string]any, opts InferOptions) {
+	// unlike format and pattern, enum also constrains values of other types,
+	// so it is only inferred where nothing but strings and nulls was seen
+	onlyStrings := s.objects+s.arrays+s.ints+s.floats+s.bools == 0
+	if onlyStrings && !s.tooMany && len(s.values) > 0 && s.strings >= len(s.values)*opts.MinEnumRepeat {
+		enum := make([]any, 0, len(s.values)+1)
+		names := make([]string, 0, len(s.values))
+		for v := range s.values {
+			names = append(names, v)
+		}
+		sort.Strings(names)
+		for _, v := range names {
+			enum = append(enum, v)
+		}
+		if s.nulls > 0 {
+			// enum is checked against every value, so null must be listed too
+			enum = append(enum, nil)
+		}
+		out["enum"] = enum
+		return
+	}
+	for _, f := range detectedFormats {
+		if s.formats[f.name] != s.strings {
+			continue
+		}
+		if f.pattern != "" {
+			out["pattern"] = f.pattern
+		} else {
+			out["format"] = f.name
+		}
+		return
+	}
+}
+
+func sortedNames(m map[string]*shape) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	numericString = `^-?[0-9]+(\.[0-9]+)?$`
+	numericRegexp = regexp.MustCompile(numericString)
+)
+
+// detectedFormats lists the string shapes Infer recognises, most specific
+// first. Entries with a pattern are emitted as "pattern" since JSON Schema
+// has no format for them.
+var detectedFormats = []struct {
+	name    string
+	pattern string
+	match   func(string) bool
+}{
+	{name: "date-time", match: formats["date-time"]},
+	{name: "uuid", match: formats["uuid"]},
+	{name: "email", match: formats["email"]},
+	{name: "uri", match: formats["uri"]},
+	{name: "hostname", match: formats["hostname"]},
+	{name: "numeric", pattern: numericString, match: numericRegexp.MatchString},
+}
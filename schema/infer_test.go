@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"json-parser/parser"
+	"os"
+	"testing"
+)
+
+func TestInferMergesTypes(t *testing.T) {
+	got := Infer(InferOptions{}, parse(`[
+		{"id":1,"score":2,"name":"a","tags":["x"],"note":null},
+		{"id":2,"score":2.5,"name":"b","tags":[],"note":"hi"},
+		{"id":3,"score":1,"name":"c","tags":["y","z"]}
+	]`))
+	expected := parse(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "array",
+		"items": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"score": {"type": "number"},
+				"name": {"type": "string"},
+				"tags": {"type": "array", "items": {"type": "string"}},
+				"note": {"type": ["string", "null"]}
+			},
+			"required": ["id", "name", "score", "tags"]
+		}
+	}`)
+	if !parser.Equal(got, expected) {
+		out, _ := parser.MarshalIndent(got, "", "  ")
+		t.Fatalf("unexpected schema:\n%s", out)
+	}
+}
+
+func TestInferStringShapes(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		key    string
+		want   any
+	}{
+		{"email", `["a@example.com","b@example.org"]`, "format", "email"},
+		{"uri", `["http://x.org/a","https://y.com"]`, "format", "uri"},
+		{"hostname", `["hildegard.org","anastasia.net"]`, "format", "hostname"},
+		{"date-time", `["2024-01-02T03:04:05Z","2023-12-31T23:59:59+01:00"]`, "format", "date-time"},
+		{"uuid", `["123e4567-e89b-12d3-a456-426614174000","00000000-0000-0000-0000-000000000000"]`, "format", "uuid"},
+		{"numeric", `["-37.3159","81.1496"]`, "pattern", numericString},
+		{"enum", `["open","closed","open","open","closed"]`, "enum", []any{"closed", "open"}},
+		{"mixed", `["a@example.com","hello"]`, "format", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Infer(InferOptions{}, parse(tt.values))
+			items := got["items"].(map[string]any)
+			if !parser.Equal(items[tt.key], tt.want) {
+				t.Fatalf("want %s=%v, got %#v", tt.key, tt.want, items)
+			}
+		})
+	}
+}
+
+func TestInferAcrossSamples(t *testing.T) {
+	got := Infer(InferOptions{MaxEnumValues: -1}, parse(`{"a":1,"b":"x"}`), parse(`{"a":2}`))
+	if !parser.Equal(got["required"], []any{"a"}) {
+		t.Fatalf("expected only a to be required, got %v", got["required"])
+	}
+}
+
+func TestInferValidatesSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []string
+	}{
+		{"enum beside integer", []string{`[{"a":"x"},{"a":"x"},{"a":"x"},{"a":1}]`}},
+		{"enum beside object", []string{`["x","x","x",{"b":true}]`}},
+		{"enum with null", []string{`["x","x",null,"y","y"]`}},
+		{"enum across samples", []string{`{"a":"x"}`, `{"a":"x"}`, `{"a":false}`}},
+		{"format beside number", []string{`["a@example.com","b@example.org",2.5]`}},
+		{"mixed items", []string{`[[1,"a"],[],["a","a",null]]`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			samples := make([]any, len(tt.samples))
+			for i, s := range tt.samples {
+				samples[i] = parse(s)
+			}
+			inferred := Infer(InferOptions{}, samples...)
+			s, err := Compile(inferred)
+			if err != nil {
+				t.Fatalf("inferred schema does not compile: %v", err)
+			}
+			for i, sample := range samples {
+				if err := s.Validate(sample); err != nil {
+					out, _ := parser.Marshal(inferred)
+					t.Fatalf("sample %d does not validate against %s: %v", i, out, err)
+				}
+			}
+		})
+	}
+}
+
+func TestInferTestData(t *testing.T) {
+	for _, name := range []string{"albums", "posts", "todos", "users"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := os.Open("../test_data/example_" + name + ".json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			doc := parser.BasicParase(f)
+
+			inferred := Infer(InferOptions{}, doc)
+			s, err := Compile(inferred)
+			if err != nil {
+				t.Fatalf("inferred schema does not compile: %v", err)
+			}
+			if err := s.Validate(doc); err != nil {
+				t.Fatalf("sample does not validate against its own schema: %v", err)
+			}
+			if _, err := parser.Marshal(inferred); err != nil {
+				t.Fatalf("inferred schema does not encode: %v", err)
+			}
+		})
+	}
+}
+
+func TestInferUsersGeo(t *testing.T) {
+	f, err := os.Open("../test_data/example_users.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	inferred := Infer(InferOptions{}, parser.BasicParase(f))
+
+	p, _ := parser.ParsePointer("/items/properties/address/properties/geo/properties/lat/pattern")
+	if got, err := p.Get(inferred); err != nil || got != numericString {
+		t.Errorf("expected numeric pattern for geo.lat, got %v (%v)", got, err)
+	}
+	p, _ = parser.ParsePointer("/items/properties/email/format")
+	if got, err := p.Get(inferred); err != nil || got != "email" {
+		t.Errorf("expected email format, got %v (%v)", got, err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package schema validates parsed JSON values against JSON Schema draft
+// 2020-12 documents. Only local references are resolved: fragments within
+// the same schema and, for schemas loaded from disk, relative file paths.
+package schema
+
+import (
+	"fmt"
+	"json-parser/parser"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Schema is a compiled schema document ready for validation. It is safe for
+// concurrent use.
+type Schema struct {
+	root *document
+
+	mu       sync.Mutex
+	docs     map[string]*document // schema files loaded through $ref, by absolute path
+	patterns map[string]*regexp.Regexp
+}
+
+// document is one schema file; refs inside it resolve against root and dir.
+type document struct {
+	root any
+	dir  string
+}
+
+// Compile prepares a parsed schema. Every pattern is compiled and every
+// local $ref is resolved up front so mistakes in the schema surface here
+// rather than during validation.
+func Compile(v any) (*Schema, error) {
+	return compile(v, "")
+}
+
+// Load reads and compiles the schema file at path. References to other
+// files are resolved relative to its directory.
+func Load(path string) (*Schema, error) {
+	v, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return compile(v, filepath.Dir(path))
+}
+
+func compile(v any, dir string) (*Schema, error) {
+	s := &Schema{
+		root:     &document{root: v, dir: dir},
+		docs:     make(map[string]*document),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+	if err := s.check(s.root, v, parser.Pointer{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func readFile(path string) (any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	v, err := parser.ParseDialect(f, parser.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", path, err)
+	}
+	return v, nil
+}
+
+// check walks a schema document verifying patterns and references.
+func (s *Schema) check(doc *document, node any, at parser.Pointer) error {
+	switch n := node.(type) {
+	case bool:
+		return nil
+	case map[string]any:
+		if p, ok := n["pattern"].(string); ok {
+			if _, err := s.regexp(p); err != nil {
+				return fmt.Errorf("schema %s/pattern: %w", at, err)
+			}
+		}
+		if props, ok := n["patternProperties"].(map[string]any); ok {
+			for p := range props {
+				if _, err := s.regexp(p); err != nil {
+					return fmt.Errorf("schema %s/patternProperties: %w", at, err)
+				}
+			}
+		}
+		if ref, ok := n["$ref"].(string); ok {
+			if _, _, err := s.resolve(doc, ref); err != nil {
+				return fmt.Errorf("schema %s/$ref: %w", at, err)
+			}
+		}
+		for k, child := range n {
+			switch k {
+			case "enum", "const", "required", "type", "examples", "default":
+				// values, not subschemas
+				continue
+			}
+			if err := s.check(doc, child, at.Append(k)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, child := range n {
+			if err := s.check(doc, child, at.Append(fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) regexp(pattern string) (*regexp.Regexp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if re, ok := s.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[pattern] = re
+	return re, nil
+}
+
+// resolve follows a $ref from within doc and returns the document the
+// target lives in together with the target subschema.
+func (s *Schema) resolve(doc *document, ref string) (*document, any, error) {
+	file, fragment, _ := strings.Cut(ref, "#")
+	target := doc
+	if file != "" {
+		if strings.Contains(file, "://") {
+			return nil, nil, fmt.Errorf("remote reference %q is not supported", ref)
+		}
+		var err error
+		if target, err = s.load(filepath.Join(doc.dir, file)); err != nil {
+			return nil, nil, fmt.Errorf("reference %q: %w", ref, err)
+		}
+	}
+	fragment, err := url.PathUnescape(fragment)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reference %q: %w", ref, err)
+	}
+	if fragment != "" && fragment[0] != '/' {
+		node, ok := findAnchor(target.root, fragment)
+		if !ok {
+			return nil, nil, fmt.Errorf("reference %q: anchor not found", ref)
+		}
+		return target, node, nil
+	}
+	p, err := parser.ParsePointer(fragment)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reference %q: %w", ref, err)
+	}
+	node, err := p.Get(target.root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reference %q: %w", ref, err)
+	}
+	return target, node, nil
+}
+
+func (s *Schema) load(path string) (*document, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	doc, ok := s.docs[abs]
+	s.mu.Unlock()
+	if ok {
+		return doc, nil
+	}
+	v, err := readFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	doc = &document{root: v, dir: filepath.Dir(abs)}
+	s.mu.Lock()
+	s.docs[abs] = doc
+	s.mu.Unlock()
+	if err := s.check(doc, v, parser.Pointer{}); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// findAnchor searches a schema document for a subschema declaring $anchor.
+func findAnchor(node any, name string) (any, bool) {
+	switch n := node.(type) {
+	case map[string]any:
+		if a, ok := n["$anchor"].(string); ok && a == name {
+			return n, true
+		}
+		for _, child := range n {
+			if found, ok := findAnchor(child, name); ok {
+				return found, true
+			}
+		}
+	case []any:
+		for _, child := range n {
+			if found, ok := findAnchor(child, name); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
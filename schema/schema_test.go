@@ -0,0 +1,218 @@
+package schema
+
+import (
+	"errors"
+	"json-parser/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func parse(input string) any {
+	return parser.BasicParase(strings.NewReader(input))
+}
+
+func mustCompile(t *testing.T, input string) *Schema {
+	t.Helper()
+	s, err := Compile(parse(input))
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	return s
+}
+
+func TestValidateKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		schema   string
+		instance string
+		valid    bool
+	}{
+		{"type string ok", `{"type":"string"}`, `"x"`, true},
+		{"type string bad", `{"type":"string"}`, `1`, false},
+		{"integer accepts whole float", `{"type":"integer"}`, `2.0`, true},
+		{"integer rejects fraction", `{"type":"integer"}`, `2.5`, false},
+		{"number accepts integer", `{"type":"number"}`, `2`, true},
+		{"type list", `{"type":["string","null"]}`, `null`, true},
+		{"boolean schema false", `false`, `1`, false},
+		{"boolean schema true", `true`, `{"a":1}`, true},
+		{"enum", `{"enum":["a",1,null]}`, `1`, true},
+		{"enum miss", `{"enum":["a",1,null]}`, `"b"`, false},
+		{"const", `{"const":{"a":[1]}}`, `{"a":[1]}`, true},
+		{"minimum", `{"minimum":3}`, `2`, false},
+		{"exclusiveMaximum", `{"exclusiveMaximum":3}`, `3`, false},
+		{"multipleOf", `{"multipleOf":0.1}`, `0.3`, true},
+		{"multipleOf miss", `{"multipleOf":2}`, `3`, false},
+		{"maxLength counts runes", `{"maxLength":2}`, `"éé"`, true},
+		{"minLength", `{"minLength":2}`, `"a"`, false},
+		{"pattern", `{"pattern":"^[a-z]+$"}`, `"abc"`, true},
+		{"pattern miss", `{"pattern":"^[a-z]+$"}`, `"ab1"`, false},
+		{"required", `{"required":["id"]}`, `{"name":"x"}`, false},
+		{"additionalProperties false", `{"properties":{"a":{}},"additionalProperties":false}`, `{"a":1,"b":2}`, false},
+		{"additionalProperties schema", `{"properties":{"a":{}},"additionalProperties":{"type":"string"}}`, `{"a":1,"b":"x"}`, true},
+		{"patternProperties", `{"patternProperties":{"^x-":{"type":"string"}},"additionalProperties":false}`, `{"x-a":"1"}`, true},
+		{"items", `{"items":{"type":"number"}}`, `[1,2,"3"]`, false},
+		{"prefixItems", `{"prefixItems":[{"type":"string"}],"items":{"type":"number"}}`, `["a",1,2]`, true},
+		{"prefixItems miss", `{"prefixItems":[{"type":"string"}],"items":false}`, `["a",1]`, false},
+		{"uniqueItems", `{"uniqueItems":true}`, `[1,{"a":1},{"a":1}]`, false},
+		{"allOf", `{"allOf":[{"type":"number"},{"minimum":5}]}`, `4`, false},
+		{"anyOf", `{"anyOf":[{"type":"string"},{"minimum":5}]}`, `6`, true},
+		{"anyOf miss", `{"anyOf":[{"type":"string"},{"minimum":5}]}`, `4`, false},
+		{"oneOf", `{"oneOf":[{"type":"integer"},{"minimum":5}]}`, `3`, true},
+		{"oneOf both", `{"oneOf":[{"type":"integer"},{"minimum":5}]}`, `6`, false},
+		{"not", `{"not":{"type":"null"}}`, `null`, false},
+		{"if then", `{"if":{"properties":{"kind":{"const":"a"}}},"then":{"required":["a"]},"else":{"required":["b"]}}`, `{"kind":"a","b":1}`, false},
+		{"if else", `{"if":{"properties":{"kind":{"const":"a"}}},"then":{"required":["a"]},"else":{"required":["b"]}}`, `{"kind":"z","b":1}`, true},
+		{"ref defs", `{"$defs":{"pos":{"minimum":0}},"properties":{"n":{"$ref":"#/$defs/pos"}}}`, `{"n":-1}`, false},
+		{"ref anchor", `{"$defs":{"s":{"$anchor":"str","type":"string"}},"items":{"$ref":"#str"}}`, `["a","b"]`, true},
+		{"recursive ref", `{"type":"object","properties":{"child":{"$ref":"#"}},"additionalProperties":false}`, `{"child":{"child":{"x":1}}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mustCompile(t, tt.schema).Validate(parse(tt.instance))
+			if tt.valid && err != nil {
+				t.Fatalf("expected valid, got %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Fatalf("expected validation error")
+			}
+		})
+	}
+}
+
+func TestValidateFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{"date-time", "2024-03-01T12:30:00Z", true},
+		{"date-time", "2024-03-01T12:30:00.5+02:00", true},
+		{"date-time", "2024-03-01", false},
+		{"email", "Sincere@april.biz", true},
+		{"email", "not an email", false},
+		{"uri", "http://hildegard.org", true},
+		{"uri", "hildegard.org", false},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000", true},
+		{"uuid", "123e4567-e89b-12d3-a456", false},
+		{"unknown-format", "anything", true},
+	}
+	for _, tt := range tests {
+		s := mustCompile(t, `{"format":"`+tt.format+`"}`)
+		err := s.Validate(tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("format %s %q: expected valid=%v, got %v", tt.format, tt.value, tt.valid, err)
+		}
+	}
+}
+
+func TestValidationErrorLocations(t *testing.T) {
+	s := mustCompile(t, `{
+		"$defs": {"geo": {"properties": {"lat": {"type": "string"}}}},
+		"properties": {
+			"address": {"properties": {"geo": {"$ref": "#/$defs/geo"}}},
+			"tags": {"items": {"type": "string"}}
+		},
+		"required": ["id"]
+	}`)
+	err := s.Validate(parse(`{"address":{"geo":{"lat":1}},"tags":["a",2]}`))
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	expected := []struct{ instance, keyword string }{
+		{"", "/required"},
+		{"/address/geo/lat", "/properties/address/properties/geo/$ref/properties/lat/type"},
+		{"/tags/1", "/properties/tags/items/type"},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i, e := range errs {
+		if e.InstanceLocation != expected[i].instance || e.KeywordLocation != expected[i].keyword {
+			t.Errorf("error %d: want %s at %s, got %s at %s", i, expected[i].keyword, expected[i].instance, e.KeywordLocation, e.InstanceLocation)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"bad pattern", `{"properties":{"a":{"pattern":"(["}}}`},
+		{"dangling ref", `{"items":{"$ref":"#/$defs/missing"}}`},
+		{"remote ref", `{"$ref":"https://example.com/schema.json"}`},
+	}
+	for _, tt := range tests {
+		if _, err := Compile(parse(tt.schema)); err == nil {
+			t.Errorf("%s: expected compile error", tt.name)
+		}
+	}
+}
+
+func TestLoadFileReferences(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("root.json", `{"properties":{"geo":{"$ref":"defs/geo.json#/$defs/point"}}}`)
+	if err := os.MkdirAll(filepath.Join(dir, "defs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write("defs/geo.json", `{"$defs":{"point":{"required":["lat","lng"],"properties":{"lat":{"$ref":"#/$defs/coord"}}},"coord":{"type":"number"}}}`)
+
+	s, err := Load(filepath.Join(dir, "root.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Validate(parse(`{"geo":{"lat":1,"lng":2}}`)); err != nil {
+		t.Errorf("expected valid, got %v", err)
+	}
+	if err := s.Validate(parse(`{"geo":{"lat":"1"}}`)); err == nil {
+		t.Errorf("expected validation errors")
+	}
+}
+
+func TestLoadMalformed(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("truncated.json", `{"type": "string", "minLength": 3 garbage`)
+	write("ref.json", `{"$ref": "truncated.json"}`)
+	for _, name := range []string{"truncated.json", "ref.json"} {
+		if _, err := Load(filepath.Join(dir, name)); err == nil || !strings.Contains(err.Error(), "truncated.json") {
+			t.Errorf("%s: expected an error naming truncated.json, got %v", name, err)
+		}
+	}
+}
+
+func TestValidateTestDataUsers(t *testing.T) {
+	s, err := Load("../test_data/users.schema.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, err := os.Open("../test_data/example_users.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	users := parser.BasicParase(f)
+	if err := s.Validate(users); err != nil {
+		t.Fatalf("expected users to validate, got %v", err)
+	}
+
+	users.([]any)[2].(map[string]any)["email"] = "nope"
+	err = s.Validate(users)
+	var errs ValidationErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].InstanceLocation != "/2/email" {
+		t.Fatalf("expected a single error at /2/email, got %v", err)
+	}
+}
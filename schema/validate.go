@@ -0,0 +1,404 @@
+package schema
+
+import (
+	"fmt"
+	"json-parser/parser"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is a single schema violation. InstanceLocation points at the
+// offending value, KeywordLocation at the schema keyword that rejected it;
+// both are JSON Pointers.
+type ValidationError struct {
+	InstanceLocation string
+	KeywordLocation  string
+	Message          string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s (schema %s)", displayPointer(e.InstanceLocation), e.Message, displayPointer(e.KeywordLocation))
+}
+
+func displayPointer(p string) string {
+	if p == "" {
+		return "/"
+	}
+	return p
+}
+
+// ValidationErrors is returned by Validate when the instance does not
+// conform.
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// maxRefDepth bounds $ref chains so a schema that references itself without
+// descending into the instance cannot recurse forever.
+const maxRefDepth = 256
+
+// Validate checks instance against the schema. It returns nil when the
+// instance is valid and ValidationErrors otherwise.
+func (s *Schema) Validate(instance any) error {
+	v := validator{s: s}
+	errs := v.validate(s.root, s.root.root, instance, parser.Pointer{}, parser.Pointer{}, 0)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+type validator struct {
+	s *Schema
+}
+
+func fail(inst, kw parser.Pointer, keyword, format string, args ...any) *ValidationError {
+	return &ValidationError{
+		InstanceLocation: inst.String(),
+		KeywordLocation:  kw.Append(keyword).String(),
+		Message:          fmt.Sprintf(format, args...),
+	}
+}
+
+func (v *validator) validate(doc *document, schema, inst any, instAt, kwAt parser.Pointer, depth int) ValidationErrors {
+	switch sch := schema.(type) {
+	case bool:
+		if !sch {
+			return ValidationErrors{{InstanceLocation: instAt.String(), KeywordLocation: kwAt.String(), Message: "no value is allowed here"}}
+		}
+		return nil
+	case map[string]any:
+		return v.validateObject(doc, sch, inst, instAt, kwAt, depth)
+	default:
+		return nil
+	}
+}
+
+func (v *validator) validateObject(doc *document, sch map[string]any, inst any, instAt, kwAt parser.Pointer, depth int) ValidationErrors {
+	var errs ValidationErrors
+
+	if ref, ok := sch["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			return append(errs, fail(instAt, kwAt, "$ref", "reference depth limit exceeded"))
+		}
+		target, sub, err := v.s.resolve(doc, ref)
+		if err != nil {
+			errs = append(errs, fail(instAt, kwAt, "$ref", "%v", err))
+		} else {
+			errs = append(errs, v.validate(target, sub, inst, instAt, kwAt.Append("$ref"), depth+1)...)
+		}
+	}
+
+	if t, ok := sch["type"]; ok && !matchesType(t, inst) {
+		errs = append(errs, fail(instAt, kwAt, "type", "expected %s, got %s", describeType(t), typeOf(inst)))
+	}
+	if enum, ok := sch["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if parser.Equal(e, inst) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fail(instAt, kwAt, "enum", "value is not one of the allowed values"))
+		}
+	}
+	if c, ok := sch["const"]; ok && !parser.Equal(c, inst) {
+		errs = append(errs, fail(instAt, kwAt, "const", "value does not equal the constant"))
+	}
+
+	switch x := inst.(type) {
+	case float64:
+		errs = append(errs, v.validateNumber(sch, x, instAt, kwAt)...)
+	case string:
+		errs = append(errs, v.validateString(sch, x, instAt, kwAt)...)
+	case []any:
+		errs = append(errs, v.validateArray(doc, sch, x, instAt, kwAt, depth)...)
+	case map[string]any:
+		errs = append(errs, v.validateProperties(doc, sch, x, instAt, kwAt, depth)...)
+	}
+
+	errs = append(errs, v.validateApplicators(doc, sch, inst, instAt, kwAt, depth)...)
+	return errs
+}
+
+func (v *validator) validateApplicators(doc *document, sch map[string]any, inst any, instAt, kwAt parser.Pointer, depth int) ValidationErrors {
+	var errs ValidationErrors
+	if all, ok := sch["allOf"].([]any); ok {
+		for i, sub := range all {
+			errs = append(errs, v.validate(doc, sub, inst, instAt, kwAt.Append("allOf").Append(strconv.Itoa(i)), depth)...)
+		}
+	}
+	if anyOf, ok := sch["anyOf"].([]any); ok {
+		var collected ValidationErrors
+		matched := false
+		for i, sub := range anyOf {
+			subErrs := v.validate(doc, sub, inst, instAt, kwAt.Append("anyOf").Append(strconv.Itoa(i)), depth)
+			if len(subErrs) == 0 {
+				matched = true
+				break
+			}
+			collected = append(collected, subErrs...)
+		}
+		if !matched {
+			errs = append(errs, fail(instAt, kwAt, "anyOf", "value does not match any of the subschemas"))
+			errs = append(errs, collected...)
+		}
+	}
+	if oneOf, ok := sch["oneOf"].([]any); ok {
+		var matches []string
+		for i, sub := range oneOf {
+			if len(v.validate(doc, sub, inst, instAt, kwAt.Append("oneOf").Append(strconv.Itoa(i)), depth)) == 0 {
+				matches = append(matches, strconv.Itoa(i))
+			}
+		}
+		switch len(matches) {
+		case 1:
+		case 0:
+			errs = append(errs, fail(instAt, kwAt, "oneOf", "value does not match any of the subschemas"))
+		default:
+			errs = append(errs, fail(instAt, kwAt, "oneOf", "value matches more than one subschema (%s)", strings.Join(matches, ", ")))
+		}
+	}
+	if not, ok := sch["not"]; ok {
+		if len(v.validate(doc, not, inst, instAt, kwAt.Append("not"), depth)) == 0 {
+			errs = append(errs, fail(instAt, kwAt, "not", "value must not match the subschema"))
+		}
+	}
+	if cond, ok := sch["if"]; ok {
+		if len(v.validate(doc, cond, inst, instAt, kwAt.Append("if"), depth)) == 0 {
+			if then, ok := sch["then"]; ok {
+				errs = append(errs, v.validate(doc, then, inst, instAt, kwAt.Append("then"), depth)...)
+			}
+		} else if els, ok := sch["else"]; ok {
+			errs = append(errs, v.validate(doc, els, inst, instAt, kwAt.Append("else"), depth)...)
+		}
+	}
+	return errs
+}
+
+func (v *validator) validateNumber(sch map[string]any, x float64, instAt, kwAt parser.Pointer) ValidationErrors {
+	var errs ValidationErrors
+	if m, ok := sch["minimum"].(float64); ok && x < m {
+		errs = append(errs, fail(instAt, kwAt, "minimum", "%v is less than the minimum %v", x, m))
+	}
+	if m, ok := sch["maximum"].(float64); ok && x > m {
+		errs = append(errs, fail(instAt, kwAt, "maximum", "%v is greater than the maximum %v", x, m))
+	}
+	if m, ok := sch["exclusiveMinimum"].(float64); ok && x <= m {
+		errs = append(errs, fail(instAt, kwAt, "exclusiveMinimum", "%v must be greater than %v", x, m))
+	}
+	if m, ok := sch["exclusiveMaximum"].(float64); ok && x >= m {
+		errs = append(errs, fail(instAt, kwAt, "exclusiveMaximum", "%v must be less than %v", x, m))
+	}
+	if m, ok := sch["multipleOf"].(float64); ok && m > 0 {
+		q := x / m
+		if math.IsInf(q, 0) || math.Abs(q-math.Round(q)) > 1e-9 {
+			errs = append(errs, fail(instAt, kwAt, "multipleOf", "%v is not a multiple of %v", x, m))
+		}
+	}
+	return errs
+}
+
+func (v *validator) validateString(sch map[string]any, x string, instAt, kwAt parser.Pointer) ValidationErrors {
+	var errs ValidationErrors
+	n := float64(utf8.RuneCountInString(x))
+	if m, ok := sch["minLength"].(float64); ok && n < m {
+		errs = append(errs, fail(instAt, kwAt, "minLength", "string is shorter than %v characters", m))
+	}
+	if m, ok := sch["maxLength"].(float64); ok && n > m {
+		errs = append(errs, fail(instAt, kwAt, "maxLength", "string is longer than %v characters", m))
+	}
+	if p, ok := sch["pattern"].(string); ok {
+		if re, err := v.s.regexp(p); err == nil && !re.MatchString(x) {
+			errs = append(errs, fail(instAt, kwAt, "pattern", "string does not match pattern %q", p))
+		}
+	}
+	if f, ok := sch["format"].(string); ok {
+		if check, known := formats[f]; known && !check(x) {
+			errs = append(errs, fail(instAt, kwAt, "format", "string is not a valid %s", f))
+		}
+	}
+	return errs
+}
+
+func (v *validator) validateArray(doc *document, sch map[string]any, arr []any, instAt, kwAt parser.Pointer, depth int) ValidationErrors {
+	var errs ValidationErrors
+	n := float64(len(arr))
+	if m, ok := sch["minItems"].(float64); ok && n < m {
+		errs = append(errs, fail(instAt, kwAt, "minItems", "array has fewer than %v items", m))
+	}
+	if m, ok := sch["maxItems"].(float64); ok && n > m {
+		errs = append(errs, fail(instAt, kwAt, "maxItems", "array has more than %v items", m))
+	}
+	if u, ok := sch["uniqueItems"].(bool); ok && u {
+	outer:
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if parser.Equal(arr[i], arr[j]) {
+					errs = append(errs, fail(instAt, kwAt, "uniqueItems", "items %d and %d are equal", i, j))
+					break outer
+				}
+			}
+		}
+	}
+	prefix, _ := sch["prefixItems"].([]any)
+	for i, sub := range prefix {
+		if i >= len(arr) {
+			break
+		}
+		errs = append(errs, v.validate(doc, sub, arr[i], instAt.Append(strconv.Itoa(i)), kwAt.Append("prefixItems").Append(strconv.Itoa(i)), depth)...)
+	}
+	if items, ok := sch["items"]; ok {
+		for i := len(prefix); i < len(arr); i++ {
+			errs = append(errs, v.validate(doc, items, arr[i], instAt.Append(strconv.Itoa(i)), kwAt.Append("items"), depth)...)
+		}
+	}
+	return errs
+}
+
+func (v *validator) validateProperties(doc *document, sch map[string]any, obj map[string]any, instAt, kwAt parser.Pointer, depth int) ValidationErrors {
+	var errs ValidationErrors
+	n := float64(len(obj))
+	if m, ok := sch["minProperties"].(float64); ok && n < m {
+		errs = append(errs, fail(instAt, kwAt, "minProperties", "object has fewer than %v properties", m))
+	}
+	if m, ok := sch["maxProperties"].(float64); ok && n > m {
+		errs = append(errs, fail(instAt, kwAt, "maxProperties", "object has more than %v properties", m))
+	}
+	if req, ok := sch["required"].([]any); ok {
+		for _, r := range req {
+			name, _ := r.(string)
+			if _, present := obj[name]; !present {
+				errs = append(errs, fail(instAt, kwAt, "required", "missing required property %q", name))
+			}
+		}
+	}
+
+	props, _ := sch["properties"].(map[string]any)
+	patterns, _ := sch["patternProperties"].(map[string]any)
+	additional, hasAdditional := sch["additionalProperties"]
+
+	// iterate in a stable order so errors are reported deterministically
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		val := obj[k]
+		evaluated := false
+		if sub, ok := props[k]; ok {
+			evaluated = true
+			errs = append(errs, v.validate(doc, sub, val, instAt.Append(k), kwAt.Append("properties").Append(k), depth)...)
+		}
+		for p, sub := range patterns {
+			re, err := v.s.regexp(p)
+			if err != nil || !re.MatchString(k) {
+				continue
+			}
+			evaluated = true
+			errs = append(errs, v.validate(doc, sub, val, instAt.Append(k), kwAt.Append("patternProperties").Append(p), depth)...)
+		}
+		if !evaluated && hasAdditional {
+			if b, ok := additional.(bool); ok && !b {
+				errs = append(errs, fail(instAt.Append(k), kwAt, "additionalProperties", "property %q is not allowed", k))
+				continue
+			}
+			errs = append(errs, v.validate(doc, additional, val, instAt.Append(k), kwAt.Append("additionalProperties"), depth)...)
+		}
+	}
+	return errs
+}
+
+func typeOf(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == math.Trunc(x) && !math.IsInf(x, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func matchesType(t any, inst any) bool {
+	actual := typeOf(inst)
+	check := func(name string) bool {
+		return name == actual || (name == "number" && actual == "integer")
+	}
+	switch x := t.(type) {
+	case string:
+		return check(x)
+	case []any:
+		for _, name := range x {
+			if s, ok := name.(string); ok && check(s) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func describeType(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, 0, len(list))
+		for _, n := range list {
+			names = append(names, fmt.Sprint(n))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,63}$`)
+)
+
+// formats holds the format assertions this validator understands. Unknown
+// formats are treated as annotations and always pass.
+var formats = map[string]func(string) bool{
+	"date-time": func(s string) bool {
+		_, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s))
+		return err == nil
+	},
+	"email": func(s string) bool {
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	},
+	"uri": func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	},
+	"uuid": uuidPattern.MatchString,
+	"hostname": func(s string) bool {
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	},
+}